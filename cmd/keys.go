@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/utils"
+)
+
+// runRotateKeys generates a new RS256 signing key. Running instances pick it
+// up on their next key refresh; tokens signed by the previous key stay valid
+// until they expire.
+func runRotateKeys(args []string) error {
+	flags := flag.NewFlagSet("rotate-keys", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	if cfg.JWT.SigningAlgorithm != "RS256" {
+		return errors.New("rotate-keys requires JWT_SIGNING_ALG=RS256; HS256 tokens are signed with SECRET_KEY")
+	}
+
+	key, err := services.NewKeyService(repository.NewStore(db), utils.NewJWT(cfg.JWT), cfg.JWT.SigningAlgorithm, logger).RotateSigningKey(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Println("New signing key:", key.KID)
+	return nil
+}
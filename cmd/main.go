@@ -1,25 +1,53 @@
 package main
 
 import (
-	"log"
-	"micro/config"
-	"micro/internal/routes"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
+	"fmt"
+	"log/slog"
+	"os"
 )
 
-func main() {
-	app := fiber.New()
+const usage = `usage: micro <command> [flags]
+
+Commands:
+  serve             start the HTTP and gRPC servers (the default)
+  migrate           apply, roll back or create schema migrations
+  create-admin      create a verified admin account
+  reset-password    set a new password and sign the user out everywhere
+  rotate-keys       generate a new RS256 signing key
+  revoke-sessions   sign a user out everywhere
+  export-users      write every account as JSON
+  import-users      create the accounts of an export-users file
 
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+Run "micro <command> -h" for the flags of a command.`
+
+var commands = map[string]func(args []string) error{
+	"serve":           runServe,
+	"migrate":         runMigrate,
+	"create-admin":    runCreateAdmin,
+	"reset-password":  runResetPassword,
+	"rotate-keys":     runRotateKeys,
+	"revoke-sessions": runRevokeSessions,
+	"export-users":    runExportUsers,
+	"import-users":    runImportUsers,
+}
+
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
 	}
 
-  config.Connect()
-  api := app.Group("/api")
-	routes.AuthRoutes(api)
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		if name == "help" || name == "-h" || name == "--help" {
+			return
+		}
+		os.Exit(2)
+	}
 
-	app.Listen(":3000")
+	if err := run(args); err != nil {
+		slog.Error("command failed", "command", name, "error", err)
+		os.Exit(1)
+	}
 }
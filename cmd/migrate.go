@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"micro/config"
+	"micro/internal/migrations"
+	"strconv"
+)
+
+const migrateUsage = `usage: micro migrate <command>
+
+  up [N]         apply all pending migrations, or the next N
+  down [N]       roll back the last applied migration, or the last N
+  status         list the migrations and whether they are applied
+  create <name>  add empty up and down scripts for every driver`
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		paths, err := migrations.Create(migrations.SourceDir, args[1])
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		return err
+	}
+
+	switch args[0] {
+	case "up", "down", "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+	n, err := countArg(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, logger, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := config.Connect(cfg.Database, logger)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(db, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(n)
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		if n == 0 {
+			n = 1
+		}
+		rolledBack, err := migrator.Down(n)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	}
+	return nil
+}
+
+func countArg(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 || len(args) > 2 {
+		return 0, errors.New(migrateUsage)
+	}
+	return n, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks for value on the terminal unless it was given as a flag.
+func prompt(value *string, label string) error {
+	if *value != "" {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("reading %s: %w", strings.ToLower(label), err)
+	}
+	*value = strings.TrimSpace(line)
+	return nil
+}
+
+// promptPassword is prompt without echo when stdin is a terminal. It asks
+// twice so a typo does not lock the account out.
+func promptPassword(value *string) error {
+	if *value != "" {
+		return nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(value, "Password")
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	if string(password) != string(confirm) {
+		return fmt.Errorf("passwords do not match")
+	}
+	*value = string(password)
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"micro/internal/audit"
+	"micro/internal/events"
+	"micro/internal/grpcserver"
+	"micro/internal/handlers"
+	"micro/internal/health"
+	"micro/internal/metrics"
+	"micro/internal/middleware"
+	"micro/internal/openapi"
+	"micro/internal/provider"
+	"micro/internal/repository"
+	"micro/internal/routes"
+	"micro/internal/services"
+	"micro/internal/tracing"
+	"micro/internal/utils"
+	"micro/internal/webhooks"
+	"net"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+)
+
+// readinessTimeout bounds the dependency checks of /readyz.
+const readinessTimeout = 2 * time.Second
+
+// runServe starts the HTTP API, the gRPC API and the background workers,
+// and stops them gracefully on SIGINT or SIGTERM.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "", "HTTP listen address, overrides HTTP_ADDR")
+	grpcAddr := flags.String("grpc-addr", "", "gRPC listen address, overrides GRPC_ADDR")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		cfg.App.Addr = *addr
+	}
+	if *grpcAddr != "" {
+		cfg.GRPC.Addr = *grpcAddr
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("flushing traces", "error", err)
+		}
+	}()
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return err
+	}
+
+	m := metrics.New()
+	m.RegisterDB(sqlDB)
+
+	store := repository.NewStore(db)
+	jwt := utils.NewJWT(cfg.JWT)
+	keys := services.NewKeyService(store, jwt, cfg.JWT.SigningAlgorithm, logger)
+	if err := keys.LoadSigningKeys(ctx); err != nil {
+		return fmt.Errorf("loading signing keys: %w", err)
+	}
+
+	auth := services.NewAuthService(
+		store,
+		jwt,
+		cfg,
+		logger,
+		m,
+		m.InstrumentProvider(provider.NewGoogle(cfg.OAuth.Google)),
+		m.InstrumentProvider(provider.NewGithub(cfg.OAuth.Github)),
+	)
+	introspection := services.NewIntrospectionService(store, auth, cfg.Introspection.CacheTTL)
+
+	checker := health.New(readinessTimeout)
+	checker.Add("database", sqlDB.PingContext)
+	if cfg.JWT.SigningAlgorithm == "RS256" {
+		checker.Add("signing_key", func(context.Context) error {
+			if !jwt.HasSigningKey() {
+				return errors.New("no active signing key")
+			}
+			return nil
+		})
+	}
+
+	h := &handlers.Handler{
+		Auth:          auth,
+		Introspection: introspection,
+		Webhooks:      webhooks.NewService(store),
+		JWT:           jwt,
+		Audit:         audit.NewLog(store, logger),
+		Cookie:        cfg.Cookie,
+		Health:        checker,
+		Metrics:       m,
+		Logger:        logger,
+	}
+	mw := middleware.New(auth, cfg.Cookie, logger)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true, ErrorHandler: middleware.ErrorHandler})
+	app.Use(mw.RequestID, mw.Trace, middleware.Timeout(cfg.App.RequestTimeout), mw.AccessLog, m.Middleware)
+
+	routes.AllRoutes(app, h, mw, m, openapi.API())
+
+	// The workers stop with workersCtx and are waited for before the
+	// database is closed.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	startWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workersCtx)
+		}()
+	}
+
+	sinks := []events.Sink{webhooks.NewSink(store)}
+	if webhookURL := cfg.Events.WebhookURL; webhookURL != "" {
+		sinks = append(sinks, events.NewWebhookSink(webhookURL))
+	}
+	startWorker(func(ctx context.Context) { auth.StartAccountPurger(ctx, cfg.Account.PurgeInterval) })
+	// Every instance runs a relay and a dispatcher. They lease the rows they
+	// work on, so each message and delivery is sent by one of them.
+	startWorker(events.NewRelay(db, logger, sinks...).Run)
+	startWorker(webhooks.NewDispatcher(store, logger).Run)
+	startWorker(func(ctx context.Context) { keys.RefreshSigningKeys(ctx, time.Minute) })
+
+	listener, err := net.Listen("tcp", cfg.GRPC.Addr)
+	if err != nil {
+		stopWorkers()
+		workers.Wait()
+		return fmt.Errorf("listening for gRPC: %w", err)
+	}
+	grpcServer := grpcserver.New(auth, introspection, cfg.App.RequestTimeout, m)
+
+	serveErrs := make(chan error, 2)
+	logger.Info("gRPC server listening", "addr", listener.Addr().String())
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			serveErrs <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+	logger.Info("HTTP server listening", "addr", cfg.App.Addr)
+	go func() {
+		if err := app.Listen(cfg.App.Addr); err != nil {
+			serveErrs <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down, draining requests", "timeout", cfg.App.ShutdownTimeout)
+	case serveErr = <-serveErrs:
+		logger.Error("shutting down", "error", serveErr)
+	}
+
+	checker.ShuttingDown()
+	shutdownErr := app.ShutdownWithTimeout(cfg.App.ShutdownTimeout)
+	stopGRPC(grpcServer, cfg.App.ShutdownTimeout)
+	stopWorkers()
+	workers.Wait()
+
+	return errors.Join(serveErr, shutdownErr)
+}
+
+// stopGRPC lets in-flight RPCs finish, and cancels those still running
+// after timeout.
+func stopGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/migrations"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/utils"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// loadConfig loads the configuration and installs the logger it describes
+// as the default one.
+func loadConfig() (*config.Config, *slog.Logger, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.SetDefault(logger)
+	return cfg, logger, nil
+}
+
+// setup loads the configuration and opens the database, refusing to go on
+// when the schema is not fully migrated.
+func setup() (*config.Config, *gorm.DB, *slog.Logger, error) {
+	cfg, logger, err := loadConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db, err := config.Connect(cfg.Database, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	migrator, err := migrations.New(db, cfg.Database.Driver)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := migrator.Check(); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w (run `migrate up`)", err)
+	}
+	return cfg, db, logger, nil
+}
+
+// authService builds the AuthService used by the operator commands, which
+// never sign in through an OAuth provider.
+func authService(cfg *config.Config, db *gorm.DB, logger *slog.Logger) *services.AuthService {
+	return services.NewAuthService(repository.NewStore(db), utils.NewJWT(cfg.JWT), cfg, logger, nil)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"micro/internal/models/request"
+	"micro/internal/services"
+	"os"
+	"strconv"
+)
+
+func runCreateAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	registerRequest := new(request.RegisterRequest)
+	flags.StringVar(&registerRequest.Email, "email", "", "email of the account")
+	flags.StringVar(&registerRequest.FirstName, "first-name", "", "first name")
+	flags.StringVar(&registerRequest.LastName, "last-name", "", "last name")
+	flags.StringVar(&registerRequest.Password, "password", "", "password, prompted for when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value *string
+		label string
+	}{
+		{&registerRequest.Email, "Email"},
+		{&registerRequest.FirstName, "First name"},
+		{&registerRequest.LastName, "Last name"},
+	} {
+		if err := prompt(field.value, field.label); err != nil {
+			return err
+		}
+	}
+	if err := promptPassword(&registerRequest.Password); err != nil {
+		return err
+	}
+	if err := services.ValidateRegister(registerRequest); err != nil {
+		return err
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	user, err := authService(cfg, db, logger).CreateAdmin(context.Background(), registerRequest)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created admin %s (id %d)\n", user.Email, user.ID)
+	return nil
+}
+
+func runResetPassword(args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	resetRequest := new(request.ResetPasswordRequest)
+	flags.StringVar(&resetRequest.Email, "email", "", "email of the account")
+	flags.StringVar(&resetRequest.Password, "password", "", "new password, prompted for when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := prompt(&resetRequest.Email, "Email"); err != nil {
+		return err
+	}
+	if err := promptPassword(&resetRequest.Password); err != nil {
+		return err
+	}
+	if err := services.ValidateResetPassword(resetRequest); err != nil {
+		return err
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	user, err := authService(cfg, db, logger).ResetPassword(context.Background(), resetRequest)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Password of %s reset, every session revoked\n", user.Email)
+	return nil
+}
+
+func runRevokeSessions(args []string) error {
+	flags := flag.NewFlagSet("revoke-sessions", flag.ContinueOnError)
+	userFlag := flags.String("user", "", "id or email of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userFlag == "" {
+		flags.Usage()
+		return errors.New("--user is required")
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	auth := authService(cfg, db, logger)
+	ctx := context.Background()
+
+	userID, err := strconv.ParseUint(*userFlag, 10, 64)
+	if err != nil {
+		user, err := auth.GetUserByEmail(ctx, *userFlag)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", *userFlag, err)
+		}
+		userID = uint64(user.ID)
+	} else if _, err := auth.GetUserByID(ctx, uint(userID)); err != nil {
+		return fmt.Errorf("user %s: %w", *userFlag, err)
+	}
+
+	if err := auth.RevokeUserSessions(ctx, uint(userID)); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked every session of user %d\n", userID)
+	return nil
+}
+
+func runExportUsers(args []string) error {
+	flags := flag.NewFlagSet("export-users", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write, standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	records, err := authService(cfg, db, logger).ExportUsers(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		// The export holds password hashes.
+		file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d users\n", len(records))
+	return nil
+}
+
+func runImportUsers(args []string) error {
+	flags := flag.NewFlagSet("import-users", flag.ContinueOnError)
+	input := flags.String("i", "", "file written by export-users, standard input when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var records []request.UserRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return fmt.Errorf("reading users: %w", err)
+	}
+	var invalid []error
+	for i := range records {
+		if err := services.ValidateUserRecord(&records[i]); err != nil {
+			invalid = append(invalid, fmt.Errorf("user #%d (%s): %w", i+1, records[i].Email, err))
+		}
+	}
+	if len(invalid) > 0 {
+		return errors.Join(invalid...)
+	}
+
+	cfg, db, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	created, skipped, err := authService(cfg, db, logger).ImportUsers(context.Background(), records)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d users, skipped %d existing\n", created, skipped)
+	return nil
+}
@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// AccountConfig controls the purge of self-deleted accounts.
+type AccountConfig struct {
+	// PurgeGracePeriod is the time a self-deleted account stays restorable
+	// before it is removed for good.
+	PurgeGracePeriod time.Duration `yaml:"purge_grace_period" toml:"purge_grace_period" env:"ACCOUNT_PURGE_GRACE_PERIOD"`
+	// PurgeInterval is how often the purge job runs.
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval" env:"ACCOUNT_PURGE_INTERVAL"`
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvProduction is the APP_ENV value that turns on the production checks.
+const EnvProduction = "production"
+
+// Config is the whole service configuration. Each field can be set from an
+// optional YAML or TOML file and overridden by the environment variable in
+// its env tag.
+type Config struct {
+	App           AppConfig           `yaml:"app" toml:"app"`
+	Log           LogConfig           `yaml:"log" toml:"log"`
+	Tracing       TracingConfig       `yaml:"tracing" toml:"tracing"`
+	Database      DatabaseConfig      `yaml:"database" toml:"database"`
+	JWT           JWTConfig           `yaml:"jwt" toml:"jwt"`
+	OAuth         OAuthConfig         `yaml:"oauth" toml:"oauth"`
+	Cookie        CookieConfig        `yaml:"cookie" toml:"cookie"`
+	Registration  RegistrationConfig  `yaml:"registration" toml:"registration"`
+	Account       AccountConfig       `yaml:"account" toml:"account"`
+	Events        EventsConfig        `yaml:"events" toml:"events"`
+	Introspection IntrospectionConfig `yaml:"introspection" toml:"introspection"`
+	GRPC          GRPCConfig          `yaml:"grpc" toml:"grpc"`
+}
+
+// AppConfig holds the process wide settings.
+type AppConfig struct {
+	Env     string `yaml:"env" toml:"env" env:"APP_ENV"`
+	Addr    string `yaml:"addr" toml:"addr" env:"HTTP_ADDR"`
+	BaseURL string `yaml:"base_url" toml:"base_url" env:"APP_BASE_URL"`
+	// ShutdownTimeout bounds how long in-flight requests may drain after
+	// SIGTERM or SIGINT.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// RequestTimeout is the deadline of the context every HTTP request and
+	// unary gRPC call is served with.
+	RequestTimeout time.Duration `yaml:"request_timeout" toml:"request_timeout" env:"REQUEST_TIMEOUT"`
+}
+
+// Production reports whether the service runs with APP_ENV=production.
+func (a AppConfig) Production() bool {
+	return strings.EqualFold(a.Env, EnvProduction)
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Env:             "development",
+			Addr:            ":3000",
+			BaseURL:         "http://localhost:3000",
+			ShutdownTimeout: 15 * time.Second,
+			RequestTimeout:  30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingNone,
+			ServiceName: "micro-auth",
+			SampleRatio: 1,
+		},
+		Database: DatabaseConfig{
+			Driver:       DriverMySQL,
+			QueryTimeout: 5 * time.Second,
+		},
+		JWT: JWTConfig{
+			Secret:           DefaultJWTSecret,
+			SigningAlgorithm: "HS256",
+			Issuer:           "micro-auth",
+			Audience:         "micro",
+			ClockSkew:        30 * time.Second,
+		},
+		OAuth: OAuthConfig{
+			Timeout: 10 * time.Second,
+		},
+		Cookie: CookieConfig{
+			Name:     "access_token",
+			CSRFName: "csrf_token",
+			Secure:   true,
+			SameSite: "Lax",
+		},
+		Registration: RegistrationConfig{
+			Mode: RegistrationOpen,
+		},
+		Account: AccountConfig{
+			PurgeGracePeriod: 30 * 24 * time.Hour,
+			PurgeInterval:    time.Hour,
+		},
+		Introspection: IntrospectionConfig{
+			CacheTTL: 30 * time.Second,
+		},
+		GRPC: GRPCConfig{
+			Addr: ":50051",
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing priority, the defaults,
+// the file named by CONFIG_FILE, the .env file and the process environment,
+// and validates the result.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load .env: %w", err)
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if cfg.Database.DSN == "" { // APP_MYSQL predates DATABASE_DSN
+		cfg.Database.DSN = os.Getenv("APP_MYSQL")
+	}
+
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) normalize() {
+	c.App.BaseURL = strings.TrimSuffix(c.App.BaseURL, "/")
+	c.Log.Level = strings.ToLower(c.Log.Level)
+	c.Log.Format = strings.ToLower(c.Log.Format)
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+	c.Database.Driver = strings.ToLower(c.Database.Driver)
+	c.JWT.SigningAlgorithm = strings.ToUpper(c.JWT.SigningAlgorithm)
+	c.Registration.Mode = RegistrationMode(strings.ToLower(string(c.Registration.Mode)))
+	for i, domain := range c.Registration.AllowedDomains {
+		c.Registration.AllowedDomains[i] = strings.ToLower(strings.TrimSpace(domain))
+	}
+	c.Cookie.RedirectTo = strings.TrimSuffix(c.Cookie.RedirectTo, "/")
+	switch strings.ToLower(c.Cookie.SameSite) {
+	case "strict":
+		c.Cookie.SameSite = "Strict"
+	case "lax":
+		c.Cookie.SameSite = "Lax"
+	case "none":
+		c.Cookie.SameSite = "None"
+	}
+	if c.OAuth.Google.RedirectURL == "" {
+		c.OAuth.Google.RedirectURL = c.App.BaseURL + "/api/v1/auth/google/callback"
+	}
+	if c.OAuth.Github.RedirectURL == "" {
+		c.OAuth.Github.RedirectURL = c.App.BaseURL + "/api/v1/auth/github/callback"
+	}
+}
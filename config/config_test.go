@@ -0,0 +1,267 @@
+package config_test
+
+import (
+	"micro/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const productionSecret = "0123456789abcdef0123456789abcdef"
+
+// load runs config.Load in a fresh working directory holding files, with env
+// set on top of the process environment. The variables of a .env file are
+// unset for the test, so that godotenv.Load sets them.
+func load(t *testing.T, files, env map[string]string) (*config.Config, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, line := range strings.Split(files[".env"], "\n") {
+		if key, _, ok := strings.Cut(line, "="); ok {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+	}
+	t.Setenv("CONFIG_FILE", "")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return config.Load()
+}
+
+const yamlFile = `
+app:
+  addr: ":4000"
+  request_timeout: 10s
+log:
+  level: DEBUG
+database:
+  driver: sqlite
+  dsn: file.db
+`
+
+const tomlFile = `
+[app]
+addr = ":4000"
+request_timeout = "10s"
+
+[log]
+level = "DEBUG"
+
+[database]
+driver = "sqlite"
+dsn = "file.db"
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		check func(cfg *config.Config) bool
+	}{
+		{
+			"defaults",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db"},
+			func(cfg *config.Config) bool {
+				want := config.Default()
+				want.Database.DSN = "app.db"
+				want.OAuth.Google.RedirectURL = "http://localhost:3000/api/v1/auth/google/callback"
+				want.OAuth.Github.RedirectURL = "http://localhost:3000/api/v1/auth/github/callback"
+				return reflect.DeepEqual(cfg, want)
+			},
+		},
+		{
+			"yaml file",
+			map[string]string{"config.yaml": yamlFile},
+			map[string]string{"CONFIG_FILE": "config.yaml"},
+			func(cfg *config.Config) bool {
+				return cfg.App.Addr == ":4000" && cfg.App.RequestTimeout == 10*time.Second && cfg.Log.Level == "debug" && cfg.Database.DSN == "file.db"
+			},
+		},
+		{
+			"toml file",
+			map[string]string{"config.toml": tomlFile},
+			map[string]string{"CONFIG_FILE": "config.toml"},
+			func(cfg *config.Config) bool {
+				return cfg.App.Addr == ":4000" && cfg.App.RequestTimeout == 10*time.Second && cfg.Log.Level == "debug" && cfg.Database.DSN == "file.db"
+			},
+		},
+		{
+			".env over the file",
+			map[string]string{"config.yaml": yamlFile, ".env": "HTTP_ADDR=:5000\nREQUEST_TIMEOUT=1m30s"},
+			map[string]string{"CONFIG_FILE": "config.yaml"},
+			func(cfg *config.Config) bool {
+				return cfg.App.Addr == ":5000" && cfg.App.RequestTimeout == 90*time.Second && cfg.Database.DSN == "file.db"
+			},
+		},
+		{
+			"environment over .env",
+			map[string]string{"config.toml": tomlFile, ".env": "HTTP_ADDR=:5000\nREQUEST_TIMEOUT=1m30s"},
+			map[string]string{"CONFIG_FILE": "config.toml", "HTTP_ADDR": ":6000"},
+			func(cfg *config.Config) bool {
+				return cfg.App.Addr == ":6000" && cfg.App.RequestTimeout == 90*time.Second && cfg.Database.DSN == "file.db"
+			},
+		},
+		{
+			"empty variables are ignored",
+			map[string]string{"config.yaml": yamlFile},
+			map[string]string{"CONFIG_FILE": "config.yaml", "HTTP_ADDR": ""},
+			func(cfg *config.Config) bool { return cfg.App.Addr == ":4000" },
+		},
+		{
+			"legacy APP_MYSQL",
+			nil,
+			map[string]string{"APP_MYSQL": "legacy"},
+			func(cfg *config.Config) bool { return cfg.Database.DSN == "legacy" },
+		},
+		{
+			"DATABASE_DSN over APP_MYSQL",
+			nil,
+			map[string]string{"APP_MYSQL": "legacy", "DATABASE_DSN": "app.db"},
+			func(cfg *config.Config) bool { return cfg.Database.DSN == "app.db" },
+		},
+		{
+			"normalized lists and names",
+			nil,
+			map[string]string{
+				"DATABASE_DSN":                 "app.db",
+				"REGISTRATION_MODE":            "Domain",
+				"REGISTRATION_ALLOWED_DOMAINS": " Example.com, ,b.org",
+				"APP_BASE_URL":                 "https://auth.example.com/",
+				"JWT_SIGNING_ALG":              "rs256",
+			},
+			func(cfg *config.Config) bool {
+				return cfg.Registration.Mode == config.RegistrationDomain &&
+					reflect.DeepEqual(cfg.Registration.AllowedDomains, []string{"example.com", "b.org"}) &&
+					cfg.App.BaseURL == "https://auth.example.com" &&
+					cfg.OAuth.Google.RedirectURL == "https://auth.example.com/api/v1/auth/google/callback" &&
+					cfg.JWT.SigningAlgorithm == "RS256"
+			},
+		},
+		{
+			"production with a secret",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "APP_ENV": "production", "SECRET_KEY": productionSecret},
+			func(cfg *config.Config) bool { return cfg.App.Production() && cfg.JWT.Secret == productionSecret },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.files, tt.env)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("Load = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  []string
+	}{
+		{
+			"missing dsn",
+			nil,
+			nil,
+			[]string{"database.dsn (DATABASE_DSN) is required"},
+		},
+		{
+			"missing required file settings",
+			map[string]string{"config.yaml": "app:\n  addr: \"\"\njwt:\n  issuer: \"\"\n  audience: \"\"\ngrpc:\n  addr: \"\"\n"},
+			map[string]string{"CONFIG_FILE": "config.yaml", "DATABASE_DSN": "app.db"},
+			[]string{"app.addr (HTTP_ADDR) is required", "jwt.issuer (JWT_ISSUER) is required", "jwt.audience (JWT_AUDIENCE) is required", "grpc.addr (GRPC_ADDR) is required"},
+		},
+		{
+			"duration without a unit",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "REQUEST_TIMEOUT": "30"},
+			[]string{"REQUEST_TIMEOUT"},
+		},
+		{
+			"malformed durations",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "SHUTDOWN_TIMEOUT": "soon", "JWT_CLOCK_SKEW": "1x"},
+			[]string{"SHUTDOWN_TIMEOUT", "JWT_CLOCK_SKEW"},
+		},
+		{
+			"negative durations",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "REQUEST_TIMEOUT": "-1s", "JWT_CLOCK_SKEW": "-5s"},
+			[]string{"app.request_timeout (REQUEST_TIMEOUT) must be positive", "jwt.clock_skew (JWT_CLOCK_SKEW) must not be negative"},
+		},
+		{
+			"malformed duration in the file",
+			map[string]string{"config.yaml": "app:\n  request_timeout: soon\n"},
+			map[string]string{"CONFIG_FILE": "config.yaml", "DATABASE_DSN": "app.db"},
+			[]string{"parse config file"},
+		},
+		{
+			"unsupported file format",
+			map[string]string{"config.json": "{}"},
+			map[string]string{"CONFIG_FILE": "config.json", "DATABASE_DSN": "app.db"},
+			[]string{`unsupported format ".json"`},
+		},
+		{
+			"missing file",
+			nil,
+			map[string]string{"CONFIG_FILE": "missing.yaml", "DATABASE_DSN": "app.db"},
+			[]string{"read config file"},
+		},
+		{
+			"default secret in production",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "APP_ENV": "Production"},
+			[]string{"jwt.secret (SECRET_KEY) must not be the built-in default in production"},
+		},
+		{
+			"default secret from .env in production",
+			map[string]string{".env": "APP_ENV=production\nSECRET_KEY=" + config.DefaultJWTSecret},
+			map[string]string{"DATABASE_DSN": "app.db"},
+			[]string{"must not be the built-in default in production"},
+		},
+		{
+			"short secret in production",
+			nil,
+			map[string]string{"DATABASE_DSN": "app.db", "APP_ENV": "production", "SECRET_KEY": "short"},
+			[]string{"at least 32 characters in production"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.files, tt.env)
+			if err == nil {
+				t.Fatalf("Load = %+v, want an error", cfg)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load error = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+// CookieConfig configures the browser cookie auth mode. When Enabled, tokens
+// are also accepted from an HttpOnly cookie, protected by a CSRF
+// double-submit cookie, and OAuth callbacks redirect to RedirectTo instead
+// of answering with JSON.
+type CookieConfig struct {
+	Enabled    bool   `yaml:"enabled" toml:"enabled" env:"AUTH_COOKIE_MODE"`
+	Name       string `yaml:"name" toml:"name" env:"AUTH_COOKIE_NAME"`
+	CSRFName   string `yaml:"csrf_name" toml:"csrf_name" env:"AUTH_CSRF_COOKIE_NAME"`
+	Domain     string `yaml:"domain" toml:"domain" env:"AUTH_COOKIE_DOMAIN"`
+	Secure     bool   `yaml:"secure" toml:"secure" env:"AUTH_COOKIE_SECURE"`
+	SameSite   string `yaml:"same_site" toml:"same_site" env:"AUTH_COOKIE_SAMESITE"`
+	RedirectTo string `yaml:"redirect_to" toml:"redirect_to" env:"OAUTH_REDIRECT_URL"`
+}
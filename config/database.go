@@ -1,36 +1,129 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"micro/internal/models/entity"
-	"os"
+	"log/slog"
+	"micro/internal/logging"
+	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// Supported database drivers.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseConfig holds the database connection settings.
+type DatabaseConfig struct {
+	// Driver is one of mysql, postgres or sqlite.
+	Driver string `yaml:"driver" toml:"driver" env:"DATABASE_DRIVER"`
+	// DSN is passed to the driver as is, e.g. "user:pass@tcp(host)/db?parseTime=true"
+	// for MySQL, "host=... user=... dbname=..." for PostgreSQL and a file
+	// name or ":memory:" for SQLite. APP_MYSQL is still read when
+	// DATABASE_DSN is not set.
+	DSN string `yaml:"dsn" toml:"dsn" env:"DATABASE_DSN"`
+	// QueryTimeout bounds each query run with a context, on top of the
+	// deadline of the request it serves.
+	QueryTimeout time.Duration `yaml:"query_timeout" toml:"query_timeout" env:"DATABASE_QUERY_TIMEOUT"`
+}
 
-func Connect() error {
-	dsn := os.Getenv("APP_MYSQL")
-	fmt.Println(dsn)
+func dialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return mysql.Open(cfg.DSN), nil
+	case DriverPostgres:
+		return postgres.Open(cfg.DSN), nil
+	case DriverSQLite:
+		return sqlite.Open(cfg.DSN), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// Connect opens the database and sends GORM's logs to logger. The schema is
+// managed by the migrations package.
+func Connect(cfg DatabaseConfig, logger *slog.Logger) (*gorm.DB, error) {
+	dialect, err := dialector(cfg)
 	if err != nil {
-		fmt.Println("Failed to connect database!")
-		return err
+		return nil, err
 	}
 
-	DB = db
+	db, err := gorm.Open(dialect, &gorm.Config{Logger: logging.NewGormLogger(logger)})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the %s database: %w", cfg.Driver, err)
+	}
 
-	fmt.Println("Database is connected!")
+	if cfg.QueryTimeout > 0 {
+		if err := db.Use(queryTimeout(cfg.QueryTimeout)); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := DB.AutoMigrate(&entity.Users{}); err != nil {
-		fmt.Println("Failed to auto-migrate:", err)
-		return err
+	if cfg.Driver == DriverSQLite {
+		// SQLite allows a single writer, and every connection to ":memory:"
+		// would otherwise open its own empty database.
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
 	}
 
-	fmt.Println("Auto migration completed successfully!")
-	return nil
+	logger.Info("database connected", "driver", cfg.Driver)
+	return db, nil
+}
+
+const queryTimeoutKey = "query_timeout:scope"
+
+// queryTimeout is a GORM plugin that runs every statement with a context
+// bounded by its duration. Row and Rows are left out: their results are
+// read after the callbacks return.
+type queryTimeout time.Duration
+
+// queryScope is what the after callback needs to end a statement: the
+// context a chain ran with before, restored so that a chain running several
+// statements, such as Count then Find, does not reuse a cancelled context.
+type queryScope struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func (queryTimeout) Name() string {
+	return "query_timeout"
 }
 
+func (t queryTimeout) Initialize(db *gorm.DB) error {
+	before := func(db *gorm.DB) {
+		parent := db.Statement.Context
+		ctx, cancel := context.WithTimeout(parent, time.Duration(t))
+		db.Statement.Context = ctx
+		db.InstanceSet(queryTimeoutKey, queryScope{parent: parent, cancel: cancel})
+	}
+	after := func(db *gorm.DB) {
+		if scope, ok := db.InstanceGet(queryTimeoutKey); ok {
+			scope.(queryScope).cancel()
+			db.Statement.Context = scope.(queryScope).parent
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("query_timeout:before_create", before),
+		cb.Create().After("gorm:create").Register("query_timeout:after_create", after),
+		cb.Query().Before("gorm:query").Register("query_timeout:before_query", before),
+		cb.Query().After("gorm:query").Register("query_timeout:after_query", after),
+		cb.Update().Before("gorm:update").Register("query_timeout:before_update", before),
+		cb.Update().After("gorm:update").Register("query_timeout:after_update", after),
+		cb.Delete().Before("gorm:delete").Register("query_timeout:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("query_timeout:after_delete", after),
+		cb.Raw().Before("gorm:raw").Register("query_timeout:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("query_timeout:after_raw", after),
+	)
+}
@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	"context"
+	"micro/config"
+	"micro/internal/logging"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type note struct {
+	ID   uint
+	Text string
+}
+
+func TestQueryTimeoutSpansChainedStatements(t *testing.T) {
+	db, err := config.Connect(config.DatabaseConfig{Driver: config.DriverSQLite, DSN: ":memory:", QueryTimeout: time.Second}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.Exec("CREATE TABLE notes (id integer PRIMARY KEY, text text)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&note{Text: "first"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	chains := map[string]*gorm.DB{
+		"without a context": db,
+		"with a context":    db.WithContext(context.Background()),
+	}
+	for name, chain := range chains {
+		// A paginated list counts then finds on the same chain.
+		query := chain.Model(&note{}).Where("text <> ?", "")
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			t.Fatalf("%s: count: %v", name, err)
+		}
+		var notes []note
+		if err := query.Find(&notes).Error; err != nil || len(notes) != 1 {
+			t.Errorf("%s: find after count = %d notes, %v", name, len(notes), err)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package config
+
+// EventsConfig controls where domain events are published.
+type EventsConfig struct {
+	// WebhookURL receives every domain event. Empty disables the webhook sink.
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"EVENTS_WEBHOOK_URL"`
+}
@@ -0,0 +1,6 @@
+package config
+
+// GRPCConfig controls the gRPC server.
+type GRPCConfig struct {
+	Addr string `yaml:"addr" toml:"addr" env:"GRPC_ADDR"`
+}
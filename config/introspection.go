@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// IntrospectionConfig controls token introspection.
+type IntrospectionConfig struct {
+	// CacheTTL is how long an introspection result is reused. It bounds how
+	// late a revocation is seen.
+	CacheTTL time.Duration `yaml:"cache_ttl" toml:"cache_ttl" env:"INTROSPECTION_CACHE_TTL"`
+}
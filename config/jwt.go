@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// DefaultJWTSecret is only meant for local development; Validate refuses it
+// in production.
+const DefaultJWTSecret = "n3^|e{jJ,|UmsT(ch42^yl8x^=7#zp}q"
+
+const minProductionSecretLength = 32
+
+// JWTConfig controls how access tokens are signed and verified.
+type JWTConfig struct {
+	// Secret signs and verifies HS256 tokens.
+	Secret string `yaml:"secret" toml:"secret" env:"SECRET_KEY"`
+	// SigningAlgorithm is HS256, or RS256 to sign with rotating keys
+	// published as a JWKS.
+	SigningAlgorithm string `yaml:"signing_algorithm" toml:"signing_algorithm" env:"JWT_SIGNING_ALG"`
+	// AcceptHS256 keeps HS256 tokens signed with Secret valid in RS256 mode,
+	// while switching over from HS256. Turn it off once they have expired:
+	// until then anyone holding the secret can mint accepted tokens.
+	AcceptHS256 bool `yaml:"accept_hs256" toml:"accept_hs256" env:"JWT_ACCEPT_HS256"`
+	// Issuer and Audience are the iss and aud claims of issued tokens.
+	Issuer   string `yaml:"issuer" toml:"issuer" env:"JWT_ISSUER"`
+	Audience string `yaml:"audience" toml:"audience" env:"JWT_AUDIENCE"`
+	// ClockSkew is the leeway allowed when checking exp, nbf and iat.
+	ClockSkew time.Duration `yaml:"clock_skew" toml:"clock_skew" env:"JWT_CLOCK_SKEW"`
+}
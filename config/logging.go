@@ -0,0 +1,9 @@
+package config
+
+// LogConfig controls the structured logger.
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	// Format is json or text.
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// OAuthConfig holds the social login providers.
+type OAuthConfig struct {
+	Google OAuthProviderConfig `yaml:"google" toml:"google" env:"GOOGLE_"`
+	Github OAuthProviderConfig `yaml:"github" toml:"github" env:"GITHUB_"`
+	// Timeout bounds each call to a provider: the code exchange and every
+	// profile request.
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"OAUTH_TIMEOUT"`
+}
+
+// OAuthProviderConfig holds the client credentials of one provider. Its env
+// names are prefixed with the provider, e.g. GOOGLE_CLIENT_ID. The redirect
+// URL defaults to the provider's callback under APP_BASE_URL.
+type OAuthProviderConfig struct {
+	ClientID     string `yaml:"client_id" toml:"client_id" env:"CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" toml:"client_secret" env:"CLIENT_SECRET_KEY"`
+	RedirectURL  string `yaml:"redirect_url" toml:"redirect_url" env:"REDIRECT_URL"`
+}
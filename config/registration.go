@@ -0,0 +1,22 @@
+package config
+
+// RegistrationMode controls who is allowed to create a new account.
+type RegistrationMode string
+
+const (
+	// RegistrationOpen lets anyone sign up.
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInviteOnly only accepts sign ups backed by a pending invitation.
+	RegistrationInviteOnly RegistrationMode = "invite"
+	// RegistrationDomain accepts sign ups from the allowed email domains, or with an invitation.
+	RegistrationDomain RegistrationMode = "domain"
+	// RegistrationClosed rejects every new account, invitations included.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// RegistrationConfig controls self-service sign up.
+type RegistrationConfig struct {
+	Mode RegistrationMode `yaml:"mode" toml:"mode" env:"REGISTRATION_MODE"`
+	// AllowedDomains are the email domains accepted in domain mode.
+	AllowedDomains []string `yaml:"allowed_domains" toml:"allowed_domains" env:"REGISTRATION_ALLOWED_DOMAINS"`
+}
@@ -0,0 +1,24 @@
+package config
+
+// Tracing exporters.
+const (
+	TracingNone   = "none"
+	TracingOTLP   = "otlp"
+	TracingStdout = "stdout"
+)
+
+// TracingConfig controls OpenTelemetry tracing.
+type TracingConfig struct {
+	// Exporter is none, otlp (OTLP over HTTP) or stdout, which prints the
+	// spans for local debugging.
+	Exporter string `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	// Endpoint is the host:port of the OTLP collector. When empty the
+	// standard OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"TRACING_ENDPOINT"`
+	// Insecure sends the spans over plain HTTP.
+	Insecure    bool   `yaml:"insecure" toml:"insecure" env:"TRACING_INSECURE"`
+	ServiceName string `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Traces
+	// started upstream follow the caller's decision.
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Validate reports every invalid or missing setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.App.Addr == "" {
+		fail("app.addr (HTTP_ADDR) is required")
+	}
+	if _, err := url.ParseRequestURI(c.App.BaseURL); err != nil {
+		fail("app.base_url (APP_BASE_URL) must be an absolute URL")
+	}
+	if c.App.ShutdownTimeout <= 0 {
+		fail("app.shutdown_timeout (SHUTDOWN_TIMEOUT) must be positive")
+	}
+	if c.App.RequestTimeout <= 0 {
+		fail("app.request_timeout (REQUEST_TIMEOUT) must be positive")
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("log.level (LOG_LEVEL) must be debug, info, warn or error")
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		fail("log.format (LOG_FORMAT) must be json or text")
+	}
+	switch c.Tracing.Exporter {
+	case TracingNone, TracingOTLP, TracingStdout:
+	default:
+		fail("tracing.exporter (TRACING_EXPORTER) must be none, otlp or stdout")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1")
+	}
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres, DriverSQLite:
+	default:
+		fail("database.driver (DATABASE_DRIVER) must be mysql, postgres or sqlite")
+	}
+	if c.Database.DSN == "" {
+		fail("database.dsn (DATABASE_DSN) is required")
+	}
+	if c.Database.QueryTimeout < 0 {
+		fail("database.query_timeout (DATABASE_QUERY_TIMEOUT) must not be negative")
+	}
+
+	switch c.JWT.SigningAlgorithm {
+	case "HS256", "RS256":
+	default:
+		fail("jwt.signing_algorithm (JWT_SIGNING_ALG) must be HS256 or RS256")
+	}
+	if c.JWT.Secret == "" {
+		fail("jwt.secret (SECRET_KEY) is required")
+	}
+	if c.App.Production() {
+		if c.JWT.Secret == DefaultJWTSecret {
+			fail("jwt.secret (SECRET_KEY) must not be the built-in default in production")
+		} else if len(c.JWT.Secret) < minProductionSecretLength {
+			fail("jwt.secret (SECRET_KEY) must be at least %d characters in production", minProductionSecretLength)
+		}
+	}
+	if c.JWT.Issuer == "" {
+		fail("jwt.issuer (JWT_ISSUER) is required")
+	}
+	if c.JWT.Audience == "" {
+		fail("jwt.audience (JWT_AUDIENCE) is required")
+	}
+	if c.JWT.ClockSkew < 0 {
+		fail("jwt.clock_skew (JWT_CLOCK_SKEW) must not be negative")
+	}
+
+	if c.OAuth.Timeout <= 0 {
+		fail("oauth.timeout (OAUTH_TIMEOUT) must be positive")
+	}
+	for name, provider := range map[string]OAuthProviderConfig{"google": c.OAuth.Google, "github": c.OAuth.Github} {
+		if (provider.ClientID == "") != (provider.ClientSecret == "") {
+			fail("oauth.%s needs both client_id and client_secret", name)
+		}
+	}
+
+	switch c.Cookie.SameSite {
+	case "Strict", "Lax":
+	case "None":
+		if !c.Cookie.Secure {
+			fail("cookie.same_site (AUTH_COOKIE_SAMESITE) None requires cookie.secure")
+		}
+	default:
+		fail("cookie.same_site (AUTH_COOKIE_SAMESITE) must be Strict, Lax or None")
+	}
+	if c.Cookie.Enabled && (c.Cookie.Name == "" || c.Cookie.CSRFName == "") {
+		fail("cookie.name and cookie.csrf_name are required in cookie mode")
+	}
+
+	switch c.Registration.Mode {
+	case RegistrationOpen, RegistrationInviteOnly, RegistrationClosed:
+	case RegistrationDomain:
+		if len(c.Registration.AllowedDomains) == 0 {
+			fail("registration.allowed_domains (REGISTRATION_ALLOWED_DOMAINS) is required in domain mode")
+		}
+	default:
+		fail("registration.mode (REGISTRATION_MODE) must be open, invite, domain or closed")
+	}
+
+	if c.Account.PurgeGracePeriod <= 0 {
+		fail("account.purge_grace_period (ACCOUNT_PURGE_GRACE_PERIOD) must be positive")
+	}
+	if c.Account.PurgeInterval <= 0 {
+		fail("account.purge_interval (ACCOUNT_PURGE_INTERVAL) must be positive")
+	}
+	if c.Introspection.CacheTTL <= 0 {
+		fail("introspection.cache_ttl (INTROSPECTION_CACHE_TTL) must be positive")
+	}
+	if c.GRPC.Addr == "" {
+		fail("grpc.addr (GRPC_ADDR) is required")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+// Package apperr defines the errors reported to API clients. Each one has a
+// stable code clients can branch on and a message safe to show them; any
+// other error is reported as an internal error without its text.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Kind classifies an Error and decides its HTTP status.
+type Kind int
+
+const (
+	Internal Kind = iota
+	Invalid
+	Unauthenticated
+	Forbidden
+	NotFound
+	Conflict
+	Unavailable
+	Timeout
+)
+
+var kindStatus = map[Kind]int{
+	Internal:        http.StatusInternalServerError,
+	Invalid:         http.StatusBadRequest,
+	Unauthenticated: http.StatusUnauthorized,
+	Forbidden:       http.StatusForbidden,
+	NotFound:        http.StatusNotFound,
+	Conflict:        http.StatusConflict,
+	Unavailable:     http.StatusServiceUnavailable,
+	Timeout:         http.StatusGatewayTimeout,
+}
+
+// Error is a domain error. Errors with the same code match with errors.Is,
+// so a sentinel can be wrapped for the logs or reissued with a more precise
+// message.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	errValidation = New(Invalid, "validation_failed", "The request is invalid")
+	errNotFound   = New(NotFound, "not_found", "The requested resource was not found")
+	errTimeout    = New(Timeout, "timeout", "The request took too long to complete")
+	errInternal   = New(Internal, "internal_error", "An unexpected error occurred")
+)
+
+// Resolve returns the HTTP status and the client-facing Error for err.
+// Errors that are not recognised resolve to a generic internal error, so
+// their text never reaches the client.
+func Resolve(err error) (int, *Error) {
+	var appErr *Error
+	var fiberErr *fiber.Error
+	var validationErrs validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &appErr):
+		return kindStatus[appErr.Kind], appErr
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest, errValidation
+	case errors.As(err, &fiberErr):
+		// Fiber's own errors, such as an unknown route, carry safe messages.
+		return fiberErr.Code, &Error{Kind: kindOf(fiberErr.Code), Code: codeOf(fiberErr.Code), Message: fiberErr.Message}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, errNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, errTimeout
+	}
+	return http.StatusInternalServerError, errInternal
+}
+
+// Code returns the code err resolves to.
+func Code(err error) string {
+	_, resolved := Resolve(err)
+	return resolved.Code
+}
+
+func kindOf(status int) Kind {
+	for kind, kindStatus := range kindStatus {
+		if kindStatus == status {
+			return kind
+		}
+	}
+	if status < http.StatusInternalServerError {
+		return Invalid
+	}
+	return Internal
+}
+
+var codeReplacer = strings.NewReplacer(" ", "_", "-", "_", "'", "")
+
+// codeOf turns a status text such as "Method Not Allowed" into a code such
+// as "method_not_allowed".
+func codeOf(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(codeReplacer.Replace(text))
+}
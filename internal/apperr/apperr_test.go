@@ -0,0 +1,45 @@
+package apperr_test
+
+import (
+	"errors"
+	"fmt"
+	"micro/internal/apperr"
+	"net/http"
+	"testing"
+)
+
+var errTaken = apperr.New(apperr.Conflict, "email_taken", "Email already in use")
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"sentinel", errTaken, http.StatusConflict, "email_taken"},
+		{"wrapped", fmt.Errorf("register: %w", errTaken), http.StatusConflict, "email_taken"},
+		{"internal", errors.New("dial tcp 10.0.0.1:5432: connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resolved := apperr.Resolve(tt.err)
+			if status != tt.status || resolved.Code != tt.code {
+				t.Errorf("Resolve = %d %s, want %d %s", status, resolved.Code, tt.status, tt.code)
+			}
+			if tt.code == "internal_error" && resolved.Message == tt.err.Error() {
+				t.Error("internal error text reached the client message")
+			}
+		})
+	}
+}
+
+func TestIsMatchesCode(t *testing.T) {
+	detailed := apperr.New(apperr.Conflict, "email_taken", "jane@example.com is already in use")
+	if !errors.Is(detailed, errTaken) {
+		t.Error("errors with the same code do not match")
+	}
+	if errors.Is(detailed, apperr.New(apperr.Conflict, "other", "")) {
+		t.Error("errors with different codes match")
+	}
+}
@@ -0,0 +1,149 @@
+// Package audit records security-relevant events in an append-only table.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"micro/internal/logging"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Action string
+
+const (
+	ActionLogin              Action = "auth.login"
+	ActionRegister           Action = "auth.register"
+	ActionOAuthLogin         Action = "auth.oauth_login"
+	ActionOAuthLink          Action = "auth.oauth_link"
+	ActionInvitationCreated  Action = "invitation.created"
+	ActionInvitationRevoked  Action = "invitation.revoked"
+	ActionInvitationAccepted Action = "invitation.accepted"
+	ActionSessionRevoked     Action = "session.revoked"
+	ActionRoleChanged        Action = "user.role_changed"
+	ActionAccountDeleted     Action = "user.deleted"
+	ActionDataExported       Action = "user.data_exported"
+	ActionWebhookCreated     Action = "webhook.created"
+	ActionWebhookUpdated     Action = "webhook.updated"
+	ActionWebhookDeleted     Action = "webhook.deleted"
+
+	ActionServiceClientCreated Action = "service_client.created"
+	ActionServiceClientDeleted Action = "service_client.deleted"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Event describes a single audited action. ActorID defaults to the
+// authenticated user of the request when left nil.
+type Event struct {
+	ActorID      *uint
+	TargetUserID *uint
+	Action       Action
+	Outcome      Outcome
+	Metadata     map[string]interface{}
+}
+
+// Filter narrows down List. Zero values are ignored.
+type Filter struct {
+	ActorID      *uint
+	TargetUserID *uint
+	// InvolvedUserID matches entries where the user is either actor or target.
+	InvolvedUserID *uint
+	Action         string
+	Outcome        string
+	From           time.Time
+	To             time.Time
+	Page           int
+	PerPage        int
+}
+
+// WithDefaults returns filter with its pagination clamped to valid values.
+func (filter Filter) WithDefaults() Filter {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 || filter.PerPage > maxPerPage {
+		filter.PerPage = defaultPerPage
+	}
+	return filter
+}
+
+// Log stores audit entries in the database.
+type Log struct {
+	store  repository.Store
+	logger *slog.Logger
+}
+
+func NewLog(store repository.Store, logger *slog.Logger) *Log {
+	return &Log{store: store, logger: logger}
+}
+
+// Record stores event with the client details of c. Failing to write an
+// audit entry is logged but never fails the request being audited.
+func (l *Log) Record(c *fiber.Ctx, event Event) {
+	entry := entity.AuditLogs{
+		ActorID:      event.ActorID,
+		TargetUserID: event.TargetUserID,
+		Action:       string(event.Action),
+		Outcome:      string(event.Outcome),
+		IP:           c.IP(),
+		UserAgent:    c.Get(fiber.HeaderUserAgent),
+	}
+
+	if entry.ActorID == nil {
+		if claims := middleware.Claims(c); claims != nil {
+			entry.ActorID = &claims.UserID
+		}
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = string(OutcomeSuccess)
+	}
+
+	if len(event.Metadata) > 0 {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			logging.FromContext(c.UserContext(), l.logger).Error("failed to encode audit metadata", "action", event.Action, "error", err)
+		} else {
+			entry.Metadata = string(metadata)
+		}
+	}
+
+	if err := l.store.WithContext(c.UserContext()).AuditLogs().Create(&entry); err != nil {
+		logging.FromContext(c.UserContext(), l.logger).Error("failed to record audit event", "action", event.Action, "error", err)
+	}
+}
+
+// List returns one page of entries matching filter, newest first, along with
+// the total number of matching entries.
+func (l *Log) List(ctx context.Context, filter Filter) ([]entity.AuditLogs, int64, error) {
+	filter = filter.WithDefaults()
+	return l.store.WithContext(ctx).AuditLogs().List(repository.AuditLogFilter{
+		ActorID:        filter.ActorID,
+		TargetUserID:   filter.TargetUserID,
+		InvolvedUserID: filter.InvolvedUserID,
+		Action:         filter.Action,
+		Outcome:        filter.Outcome,
+		From:           filter.From,
+		To:             filter.To,
+	}, (filter.Page-1)*filter.PerPage, filter.PerPage)
+}
+
+// UserID returns a pointer to id, for use in Event and Filter.
+func UserID(id uint) *uint {
+	return &id
+}
@@ -0,0 +1,124 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"micro/internal/audit"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/testutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestEntriesAreAppendOnly(t *testing.T) {
+	db := testutil.OpenDB(t)
+	entry := entity.AuditLogs{Action: string(audit.ActionLogin), Outcome: string(audit.OutcomeSuccess)}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Model(&entry).Update("outcome", audit.OutcomeFailure).Error; !errors.Is(err, entity.ErrAuditLogAppendOnly) {
+		t.Errorf("update error = %v, want ErrAuditLogAppendOnly", err)
+	}
+	if err := db.Delete(&entry).Error; !errors.Is(err, entity.ErrAuditLogAppendOnly) {
+		t.Errorf("delete error = %v, want ErrAuditLogAppendOnly", err)
+	}
+
+	var stored entity.AuditLogs
+	if err := db.First(&stored, entry.ID).Error; err != nil || stored.Outcome != string(audit.OutcomeSuccess) {
+		t.Errorf("stored = %+v, %v; want the entry unchanged", stored, err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	db := testutil.OpenDB(t)
+	log := audit.NewLog(repository.NewStore(db), logging.Discard())
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		log.Record(c, audit.Event{
+			ActorID:  audit.UserID(7),
+			Action:   audit.ActionRoleChanged,
+			Metadata: map[string]interface{}{"to": "admin"},
+		})
+		return nil
+	})
+	req := httptest.NewRequest(fiber.MethodPost, "/", nil)
+	req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry entity.AuditLogs
+	if err := db.First(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+	if *entry.ActorID != 7 || entry.Outcome != string(audit.OutcomeSuccess) || entry.UserAgent != "test-agent" || entry.Metadata != `{"to":"admin"}` {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestListFiltersAndPages(t *testing.T) {
+	db := testutil.OpenDB(t)
+	log := audit.NewLog(repository.NewStore(db), logging.Discard())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Entry i is at start + i hours. Users 1 and 2 alternate as actor, user
+	// 3 is the target of every third entry and every fifth one failed.
+	for i := 0; i < 30; i++ {
+		entry := entity.AuditLogs{
+			ActorID:   audit.UserID(uint(i%2 + 1)),
+			Action:    string(audit.ActionLogin),
+			Outcome:   string(audit.OutcomeSuccess),
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		}
+		if i%3 == 0 {
+			entry.TargetUserID = audit.UserID(3)
+			entry.Action = string(audit.ActionRoleChanged)
+		}
+		if i%5 == 0 {
+			entry.Outcome = string(audit.OutcomeFailure)
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter audit.Filter
+		total  int64
+		count  int
+	}{
+		{"everything", audit.Filter{}, 30, 20},
+		{"second page", audit.Filter{Page: 2, PerPage: 20}, 30, 10},
+		{"actor", audit.Filter{ActorID: audit.UserID(1)}, 15, 15},
+		{"target", audit.Filter{TargetUserID: audit.UserID(3)}, 10, 10},
+		{"involved", audit.Filter{InvolvedUserID: audit.UserID(3)}, 10, 10},
+		{"action", audit.Filter{Action: string(audit.ActionLogin)}, 20, 20},
+		{"outcome", audit.Filter{Outcome: string(audit.OutcomeFailure)}, 6, 6},
+		{"time range", audit.Filter{From: start.Add(10 * time.Hour), To: start.Add(20 * time.Hour)}, 10, 10},
+		{"oversized page", audit.Filter{PerPage: 1000}, 30, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := log.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.total || len(entries) != tt.count {
+				t.Errorf("List = %d entries of %d, want %d of %d", len(entries), total, tt.count, tt.total)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].CreatedAt.After(entries[i-1].CreatedAt) {
+					t.Fatal("entries are not sorted newest first")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package events publishes user lifecycle events to other services. Events
+// are written to an outbox table in the same transaction as the change that
+// caused them and delivered afterwards by a Relay.
+package events
+
+import (
+	"encoding/json"
+	"micro/internal/models/entity"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Type string
+
+const (
+	UserRegistered   Type = "user.registered"
+	UserVerified     Type = "user.verified"
+	UserEmailChanged Type = "user.email_changed"
+	RoleChanged      Type = "user.role_changed"
+	UserDeleted      Type = "user.deleted"
+	UserPurged       Type = "user.purged"
+)
+
+// Types lists every event type this service emits.
+func Types() []Type {
+	return []Type{UserRegistered, UserVerified, UserEmailChanged, RoleChanged, UserDeleted, UserPurged}
+}
+
+// Event is the envelope delivered to sinks. ID is stable across retries so
+// consumers can de-duplicate, as delivery is at-least-once.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        Type            `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type UserPayload struct {
+	UserID   uint   `json:"user_id"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Verified bool   `json:"verified"`
+	Provider string `json:"provider"`
+}
+
+type EmailChangedPayload struct {
+	UserID        uint   `json:"user_id"`
+	PreviousEmail string `json:"previous_email"`
+	Email         string `json:"email"`
+}
+
+type RoleChangedPayload struct {
+	UserID       uint   `json:"user_id"`
+	PreviousRole string `json:"previous_role"`
+	Role         string `json:"role"`
+}
+
+type UserDeletedPayload struct {
+	UserID     uint      `json:"user_id"`
+	Email      string    `json:"email"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+type UserPurgedPayload struct {
+	UserID uint `json:"user_id"`
+}
+
+// NewUserPayload builds the payload shared by the user lifecycle events.
+func NewUserPayload(user *entity.Users) UserPayload {
+	provider := "default"
+	if user.Provider != nil {
+		provider = *user.Provider
+	}
+	return UserPayload{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		Verified: user.Verify,
+		Provider: provider,
+	}
+}
+
+// Emit writes an event to the outbox using tx, so that it is only published
+// if the surrounding transaction commits.
+func Emit(tx *gorm.DB, eventType Type, userID uint, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	message := entity.OutboxMessages{
+		ID:            uuid.NewString(),
+		EventType:     string(eventType),
+		AggregateID:   strconv.FormatUint(uint64(userID), 10),
+		Payload:       string(data),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}
+	return tx.Create(&message).Error
+}
+
+func fromOutbox(message *entity.OutboxMessages) Event {
+	return Event{
+		ID:          message.ID,
+		Type:        Type(message.EventType),
+		AggregateID: message.AggregateID,
+		OccurredAt:  message.OccurredAt,
+		Payload:     json.RawMessage(message.Payload),
+	}
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"micro/internal/models/entity"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRelayInterval  = 5 * time.Second
+	defaultRelayBatchSize = 100
+	defaultRelayLease     = time.Minute
+	maxRelayBackoff       = 30 * time.Minute
+)
+
+// Relay moves events from the outbox to every sink. A message is marked as
+// published once all sinks accepted it; otherwise it is retried with
+// exponential backoff, which may deliver it again to sinks that succeeded.
+//
+// Relays of several instances can share a database: a message is leased to
+// one relay for Lease before it is published, and is offered again once the
+// lease runs out if that relay stopped before recording the outcome.
+type Relay struct {
+	DB        *gorm.DB
+	Sinks     []Sink
+	Interval  time.Duration
+	BatchSize int
+	Lease     time.Duration
+	Logger    *slog.Logger
+}
+
+func NewRelay(db *gorm.DB, logger *slog.Logger, sinks ...Sink) *Relay {
+	return &Relay{
+		DB:        db,
+		Logger:    logger,
+		Sinks:     sinks,
+		Interval:  defaultRelayInterval,
+		BatchSize: defaultRelayBatchSize,
+		Lease:     defaultRelayLease,
+	}
+}
+
+// Run flushes the outbox every Interval until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Flush(ctx); err != nil {
+				r.Logger.ErrorContext(ctx, "failed to relay outbox events", "error", err)
+			}
+		}
+	}
+}
+
+// Flush delivers the pending messages that are due and returns how many
+// were published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	var messages []entity.OutboxMessages
+	err := r.DB.WithContext(ctx).
+		Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+		Order("occurred_at").
+		Limit(r.BatchSize).
+		Find(&messages).Error
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range messages {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+
+		message := &messages[i]
+		leased, err := r.lease(ctx, message)
+		if err != nil {
+			return published, err
+		}
+		if !leased {
+			continue
+		}
+
+		if err := r.publish(ctx, fromOutbox(message)); err != nil {
+			message.Attempts++
+			updates := map[string]interface{}{
+				"attempts":        message.Attempts,
+				"next_attempt_at": time.Now().Add(backoff(message.Attempts)),
+				"last_error":      err.Error(),
+			}
+			if err := r.DB.WithContext(ctx).Model(message).Updates(updates).Error; err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.DB.WithContext(ctx).Model(message).Updates(map[string]interface{}{
+			"published_at": time.Now(),
+			"last_error":   "",
+		}).Error; err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// lease postpones the next attempt of message by the lease duration, so that
+// other relays leave it alone while it is published. It reports false when
+// another relay leased or published the message first.
+func (r *Relay) lease(ctx context.Context, message *entity.OutboxMessages) (bool, error) {
+	now := time.Now()
+	result := r.DB.WithContext(ctx).Model(&entity.OutboxMessages{}).
+		Where("id = ? AND published_at IS NULL AND next_attempt_at <= ?", message.ID, now).
+		Update("next_attempt_at", now.Add(r.Lease))
+	return result.RowsAffected == 1, result.Error
+}
+
+// publish hands event to every sink, within the lease of its message.
+func (r *Relay) publish(ctx context.Context, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, r.Lease)
+	defer cancel()
+
+	var failures []string
+	for _, sink := range r.Sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// backoff doubles the wait after every failed attempt, starting at the
+// relay's base delay and capped at maxRelayBackoff.
+func backoff(attempts int) time.Duration {
+	delay := defaultRelayInterval
+	for i := 1; i < attempts && delay < maxRelayBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRelayBackoff {
+		delay = maxRelayBackoff
+	}
+	return delay
+}
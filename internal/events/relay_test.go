@@ -0,0 +1,159 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"micro/internal/events"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// funcSink publishes through a function, to fail or act on demand.
+type funcSink func(ctx context.Context, event events.Event) error
+
+func (funcSink) Name() string { return "func" }
+
+func (f funcSink) Publish(ctx context.Context, event events.Event) error {
+	return f(ctx, event)
+}
+
+func emit(t *testing.T, db *gorm.DB, eventType events.Type) {
+	t.Helper()
+	if err := events.Emit(db, eventType, 1, events.UserPurgedPayload{UserID: 1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func outbox(t *testing.T, db *gorm.DB) []entity.OutboxMessages {
+	t.Helper()
+	var messages []entity.OutboxMessages
+	if err := db.Order("occurred_at").Find(&messages).Error; err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+// due makes every unpublished message due again, skipping its backoff.
+func due(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Model(&entity.OutboxMessages{}).Where("published_at IS NULL").Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlushPublishesMessages(t *testing.T) {
+	db := testutil.OpenDB(t)
+	emit(t, db, events.UserRegistered)
+	emit(t, db, events.UserDeleted)
+	sink := events.NewMemorySink()
+	relay := events.NewRelay(db, logging.Discard(), sink)
+
+	published, err := relay.Flush(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("Flush = %d, %v; want 2", published, err)
+	}
+	if got := sink.Events(); len(got) != 2 || got[0].Type != events.UserRegistered || got[1].Type != events.UserDeleted {
+		t.Errorf("sink received %+v", got)
+	}
+	for _, message := range outbox(t, db) {
+		if message.PublishedAt == nil {
+			t.Errorf("message %s is not marked published", message.ID)
+		}
+	}
+
+	if published, err := relay.Flush(context.Background()); err != nil || published != 0 {
+		t.Errorf("second Flush = %d, %v; want nothing left to publish", published, err)
+	}
+}
+
+func TestFlushBacksOffWhenASinkFails(t *testing.T) {
+	db := testutil.OpenDB(t)
+	emit(t, db, events.UserRegistered)
+	calls := 0
+	relay := events.NewRelay(db, logging.Discard(), funcSink(func(ctx context.Context, event events.Event) error {
+		calls++
+		return errors.New("unavailable")
+	}))
+
+	before := time.Now()
+	if published, err := relay.Flush(context.Background()); err != nil || published != 0 {
+		t.Fatalf("Flush = %d, %v; want 0", published, err)
+	}
+	message := outbox(t, db)[0]
+	if message.PublishedAt != nil || message.Attempts != 1 || !strings.Contains(message.LastError, "unavailable") {
+		t.Errorf("message = %+v, want one failed attempt", message)
+	}
+	if !message.NextAttemptAt.After(before) {
+		t.Errorf("next attempt at %v, want it postponed", message.NextAttemptAt)
+	}
+
+	// Not due yet.
+	if _, err := relay.Flush(context.Background()); err != nil || calls != 1 {
+		t.Errorf("Flush during backoff called the sink %d times, %v", calls, err)
+	}
+
+	due(t, db)
+	if _, err := relay.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if message := outbox(t, db)[0]; message.Attempts != 2 || message.NextAttemptAt.Sub(time.Now()) < 5*time.Second {
+		t.Errorf("message = %+v, want a longer backoff after the second failure", message)
+	}
+}
+
+func TestFlushRedeliversAfterPartialFailure(t *testing.T) {
+	db := testutil.OpenDB(t)
+	emit(t, db, events.UserRegistered)
+	sink := events.NewMemorySink()
+	failures := 1
+	relay := events.NewRelay(db, logging.Discard(), sink, funcSink(func(ctx context.Context, event events.Event) error {
+		if failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		return nil
+	}))
+
+	if published, err := relay.Flush(context.Background()); err != nil || published != 0 {
+		t.Fatalf("Flush = %d, %v; want 0", published, err)
+	}
+	due(t, db)
+	if published, err := relay.Flush(context.Background()); err != nil || published != 1 {
+		t.Fatalf("retry Flush = %d, %v; want 1", published, err)
+	}
+
+	// The sink that succeeded the first time gets the event again, with the
+	// same id so that it can de-duplicate.
+	got := sink.Events()
+	if len(got) != 2 || got[0].ID != got[1].ID {
+		t.Errorf("sink received %+v, want the event twice", got)
+	}
+}
+
+func TestFlushSkipsMessagesLeasedByAnotherRelay(t *testing.T) {
+	db := testutil.OpenDB(t)
+	emit(t, db, events.UserRegistered)
+	sink := events.NewMemorySink()
+	other := events.NewRelay(db, logging.Discard(), sink)
+
+	// The other relay flushes while this one is publishing the message.
+	var concurrent int
+	relay := events.NewRelay(db, logging.Discard(), sink, funcSink(func(ctx context.Context, event events.Event) error {
+		var err error
+		concurrent, err = other.Flush(ctx)
+		return err
+	}))
+
+	if published, err := relay.Flush(context.Background()); err != nil || published != 1 {
+		t.Fatalf("Flush = %d, %v; want 1", published, err)
+	}
+	if concurrent != 0 || len(sink.Events()) != 1 {
+		t.Errorf("the other relay published %d messages, sink received %d events; want the message published once", concurrent, len(sink.Events()))
+	}
+}
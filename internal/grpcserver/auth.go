@@ -0,0 +1,157 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"micro/internal/models/entity"
+	"micro/internal/services"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Principal is the authenticated caller of an RPC: either a user, through
+// its access token, or a service client.
+type Principal struct {
+	User    *entity.Users
+	Session *entity.Sessions
+	Client  *entity.ServiceClients
+}
+
+// Privileged reports whether the caller may read any user: admins and
+// service clients.
+func (p *Principal) Privileged() bool {
+	return p.Client != nil || (p.User != nil && p.User.Role == "admin")
+}
+
+type principalKey struct{}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// authenticator resolves the Principal of incoming RPCs.
+type authenticator struct {
+	auth    Accounts
+	clients ServiceClients
+}
+
+// authenticate mirrors middleware.Auth: it accepts a user token from the
+// "authorization: Bearer" or "x-token" metadata, and additionally service
+// client credentials through "authorization: Basic".
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	authorization := firstValue(md, "authorization")
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+
+	switch {
+	case strings.EqualFold(scheme, "Basic"):
+		client, err := a.authenticateClient(ctx, credentials)
+		if err != nil {
+			return nil, err
+		}
+		return context.WithValue(ctx, principalKey{}, &Principal{Client: client}), nil
+	case strings.EqualFold(scheme, "Bearer"):
+		return a.authenticateUser(ctx, strings.TrimSpace(credentials))
+	case firstValue(md, "x-token") != "":
+		return a.authenticateUser(ctx, firstValue(md, "x-token"))
+	default:
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+}
+
+func (a *authenticator) authenticateUser(ctx context.Context, token string) (context.Context, error) {
+	info, err := a.auth.ValidateAccessToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrSessionRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+		return nil, status.Error(codes.Internal, "failed to validate token")
+	}
+	return context.WithValue(ctx, principalKey{}, &Principal{User: info.User, Session: info.Session}), nil
+}
+
+// authenticateClient checks Basic service client credentials. Both parts may
+// be URL-encoded, as RFC 6749 asks of clients.
+func (a *authenticator) authenticateClient(ctx context.Context, credentials string) (*entity.ServiceClients, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	id, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	if unescaped, err := url.QueryUnescape(id); err == nil {
+		id = unescaped
+	}
+	if unescaped, err := url.QueryUnescape(secret); err == nil {
+		secret = unescaped
+	}
+
+	client, err := a.clients.AuthenticateServiceClient(ctx, id, secret)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidClientCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+		return nil, status.Error(codes.Internal, "failed to authenticate client")
+	}
+	return client, nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// publicMethods skip authentication.
+var publicMethods = map[string]bool{
+	"/grpc.health.v1.Health/Check": true,
+	"/grpc.health.v1.Health/Watch": true,
+}
+
+func isPublic(fullMethod string) bool {
+	return publicMethods[fullMethod] || strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublic(info.FullMethod) {
+		return handler(srv, stream)
+	}
+
+	ctx, err := a.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,184 @@
+// Package grpcserver serves the gRPC API next to the Fiber HTTP API, on top
+// of the same services layer.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	authv1 "micro/api/proto/auth/v1"
+	"micro/internal/metrics"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxUsersPerRequest bounds GetUsersByIDs.
+const maxUsersPerRequest = 500
+
+// Accounts validates access tokens and looks users up for the RPCs.
+// *services.AuthService implements it.
+type Accounts interface {
+	ValidateAccessToken(ctx context.Context, token string) (*services.TokenInfo, error)
+	GetUserByID(ctx context.Context, id uint) (*entity.Users, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) ([]entity.Users, error)
+	CheckPermission(ctx context.Context, userID uint, permission string) (bool, string, error)
+}
+
+// ServiceClients authenticates service clients.
+// *services.IntrospectionService implements it.
+type ServiceClients interface {
+	AuthenticateServiceClient(ctx context.Context, clientID, secret string) (*entity.ServiceClients, error)
+}
+
+// New returns a gRPC server with the auth service, health checking and
+// reflection registered. clients authenticates service clients, and every
+// unary RPC is served within requestTimeout. Calls are traced like HTTP
+// requests and observed by m.
+func New(auth Accounts, clients ServiceClients, requestTimeout time.Duration, m *metrics.Metrics) *grpc.Server {
+	authn := &authenticator{auth: auth, clients: clients}
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(observe(m), timeout(requestTimeout), authn.unary),
+		grpc.ChainStreamInterceptor(authn.stream),
+	)
+
+	authv1.RegisterAuthServiceServer(server, &authServer{auth: auth})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(authv1.AuthService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
+
+type authServer struct {
+	authv1.UnimplementedAuthServiceServer
+	auth Accounts
+}
+
+func (s *authServer) ValidateToken(ctx context.Context, req *authv1.ValidateTokenRequest) (*authv1.ValidateTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	info, err := s.auth.ValidateAccessToken(ctx, req.GetToken())
+	switch {
+	case errors.Is(err, services.ErrInvalidToken):
+		return &authv1.ValidateTokenResponse{Active: false}, nil
+	case errors.Is(err, services.ErrSessionRevoked):
+		return &authv1.ValidateTokenResponse{Active: false, Revoked: info.Session.RevokedAt != nil}, nil
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to validate token")
+	}
+
+	return &authv1.ValidateTokenResponse{
+		Active:    true,
+		UserId:    uint64(info.User.ID),
+		Email:     info.User.Email,
+		Role:      info.User.Role,
+		Scope:     services.ScopeForRole(info.User.Role),
+		SessionId: info.Session.ID,
+		ExpiresAt: timestamppb.New(info.Session.ExpiresAt),
+	}, nil
+}
+
+func (s *authServer) GetUser(ctx context.Context, req *authv1.GetUserRequest) (*authv1.GetUserResponse, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if !principal.Privileged() && uint64(principal.User.ID) != req.GetId() {
+		return nil, status.Error(codes.PermissionDenied, "forbidden access")
+	}
+
+	user, err := s.auth.GetUserByID(ctx, uint(req.GetId()))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	return &authv1.GetUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *authServer) GetUsersByIDs(ctx context.Context, req *authv1.GetUsersByIDsRequest) (*authv1.GetUsersByIDsResponse, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if !principal.Privileged() {
+		return nil, status.Error(codes.PermissionDenied, "forbidden access")
+	}
+	if len(req.GetIds()) > maxUsersPerRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids per request", maxUsersPerRequest)
+	}
+
+	ids := make([]uint, 0, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		ids = append(ids, uint(id))
+	}
+
+	users, err := s.auth.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get users")
+	}
+
+	found := make(map[uint64]bool, len(users))
+	response := &authv1.GetUsersByIDsResponse{Users: make([]*authv1.User, 0, len(users))}
+	for i := range users {
+		found[uint64(users[i].ID)] = true
+		response.Users = append(response.Users, toProtoUser(&users[i]))
+	}
+	for _, id := range req.GetIds() {
+		if !found[id] {
+			response.MissingIds = append(response.MissingIds, id)
+		}
+	}
+	return response, nil
+}
+
+func (s *authServer) CheckPermission(ctx context.Context, req *authv1.CheckPermissionRequest) (*authv1.CheckPermissionResponse, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	if !principal.Privileged() && uint64(principal.User.ID) != req.GetUserId() {
+		return nil, status.Error(codes.PermissionDenied, "forbidden access")
+	}
+	if req.GetPermission() == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission is required")
+	}
+
+	allowed, role, err := s.auth.CheckPermission(ctx, uint(req.GetUserId()), req.GetPermission())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to check permission")
+	}
+
+	return &authv1.CheckPermissionResponse{Allowed: allowed, Role: role}, nil
+}
+
+func toProtoUser(user *entity.Users) *authv1.User {
+	provider := "default"
+	if user.Provider != nil {
+		provider = *user.Provider
+	}
+	return &authv1.User{
+		Id:        uint64(user.ID),
+		Name:      user.Name,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Verified:  user.Verify,
+		Provider:  provider,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
@@ -0,0 +1,251 @@
+package grpcserver_test
+
+import (
+	"context"
+	"encoding/base64"
+	authv1 "micro/api/proto/auth/v1"
+	"micro/config"
+	"micro/internal/grpcserver"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"net"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a server on an in-memory listener and returns a client of it.
+func serve(t *testing.T, auth grpcserver.Accounts, clients grpcserver.ServiceClients) authv1.AuthServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpcserver.New(auth, clients, time.Second, nil)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return authv1.NewAuthServiceClient(conn)
+}
+
+func TestServiceClientAuthentication(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	cfg := config.Default()
+	db := testutil.OpenDB(t)
+	auth := services.NewAuthService(repository.NewStore(db), utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil)
+	clients := services.NewIntrospectionService(repository.NewStore(db), auth, time.Minute)
+	client, secret, err := clients.CreateServiceClient(context.Background(), "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := serve(t, auth, clients)
+
+	call := func(clientSecret string) (*authv1.ValidateTokenResponse, error) {
+		credentials := base64.StdEncoding.EncodeToString([]byte(client.ClientID + ":" + clientSecret))
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+credentials)
+		return api.ValidateToken(ctx, &authv1.ValidateTokenRequest{Token: "not a token"})
+	}
+
+	response, err := call(secret)
+	if err != nil {
+		t.Fatalf("ValidateToken with valid client credentials: %v", err)
+	}
+	if response.GetActive() {
+		t.Error("invalid token reported active")
+	}
+	if _, err := call("wrong"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong secret error = %v, want Unauthenticated", err)
+	}
+
+	traced := 0
+	for _, span := range recorder.Ended() {
+		if span.Name() == authv1.AuthService_ValidateToken_FullMethodName[1:] {
+			traced++
+		}
+	}
+	if traced != 2 {
+		t.Errorf("%d ValidateToken server spans, want 2", traced)
+	}
+}
+
+// fakeAccounts knows users 1 and 3, members, and 2, an admin. Each token is
+// named after what it resolves to.
+type fakeAccounts struct{}
+
+var fakeUsers = map[uint]*entity.Users{
+	1: {ID: 1, Email: "member@example.com", Role: "member"},
+	2: {ID: 2, Email: "admin@example.com", Role: "admin"},
+	3: {ID: 3, Email: "other@example.com", Role: "member"},
+}
+
+func (fakeAccounts) ValidateAccessToken(ctx context.Context, token string) (*services.TokenInfo, error) {
+	session := &entity.Sessions{ID: token, ExpiresAt: time.Now().Add(time.Hour)}
+	switch token {
+	case "member":
+		return &services.TokenInfo{User: fakeUsers[1], Session: session}, nil
+	case "admin":
+		return &services.TokenInfo{User: fakeUsers[2], Session: session}, nil
+	case "revoked":
+		revokedAt := time.Now()
+		session.RevokedAt = &revokedAt
+		return &services.TokenInfo{User: fakeUsers[1], Session: session}, services.ErrSessionRevoked
+	default:
+		return nil, services.ErrInvalidToken
+	}
+}
+
+func (fakeAccounts) GetUserByID(ctx context.Context, id uint) (*entity.Users, error) {
+	if user, ok := fakeUsers[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (fakeAccounts) GetUsersByIDs(ctx context.Context, ids []uint) ([]entity.Users, error) {
+	var users []entity.Users
+	for _, id := range ids {
+		if user, ok := fakeUsers[id]; ok {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+func (fakeAccounts) CheckPermission(ctx context.Context, userID uint, permission string) (bool, string, error) {
+	user, ok := fakeUsers[userID]
+	if !ok {
+		return false, "", repository.ErrNotFound
+	}
+	return user.Role == "admin", user.Role, nil
+}
+
+type fakeClients struct{}
+
+func (fakeClients) AuthenticateServiceClient(ctx context.Context, clientID, secret string) (*entity.ServiceClients, error) {
+	if clientID != "billing" || secret != "s3cret" {
+		return nil, services.ErrInvalidClientCredentials
+	}
+	return &entity.ServiceClients{ID: 1, ClientID: clientID, Name: "billing"}, nil
+}
+
+func bearer(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func serviceClient() context.Context {
+	credentials := base64.StdEncoding.EncodeToString([]byte("billing:s3cret"))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+credentials)
+}
+
+func TestAuthorization(t *testing.T) {
+	api := serve(t, fakeAccounts{}, fakeClients{})
+
+	getUser := func(ctx context.Context, id uint64) error {
+		_, err := api.GetUser(ctx, &authv1.GetUserRequest{Id: id})
+		return err
+	}
+	getUsers := func(ctx context.Context, ids ...uint64) error {
+		_, err := api.GetUsersByIDs(ctx, &authv1.GetUsersByIDsRequest{Ids: ids})
+		return err
+	}
+	checkPermission := func(ctx context.Context, id uint64, permission string) error {
+		_, err := api.CheckPermission(ctx, &authv1.CheckPermissionRequest{UserId: id, Permission: permission})
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"member gets self", getUser(bearer("member"), 1), codes.OK},
+		{"member gets another user", getUser(bearer("member"), 3), codes.PermissionDenied},
+		{"admin gets another user", getUser(bearer("admin"), 3), codes.OK},
+		{"service client gets a user", getUser(serviceClient(), 3), codes.OK},
+		{"admin gets an unknown user", getUser(bearer("admin"), 99), codes.NotFound},
+		{"expired token", getUser(bearer("expired"), 1), codes.Unauthenticated},
+		{"revoked token", getUser(bearer("revoked"), 1), codes.Unauthenticated},
+		{"no credentials", getUser(context.Background(), 1), codes.Unauthenticated},
+
+		{"member gets users", getUsers(bearer("member"), 1), codes.PermissionDenied},
+		{"admin gets users", getUsers(bearer("admin"), 1, 3), codes.OK},
+		{"service client gets users", getUsers(serviceClient(), 1, 3), codes.OK},
+		{"too many ids", getUsers(serviceClient(), make([]uint64, 501)...), codes.InvalidArgument},
+		{"revoked token gets users", getUsers(bearer("revoked"), 1), codes.Unauthenticated},
+
+		{"member checks self", checkPermission(bearer("member"), 1, "users:write"), codes.OK},
+		{"member checks another user", checkPermission(bearer("member"), 3, "users:write"), codes.PermissionDenied},
+		{"admin checks another user", checkPermission(bearer("admin"), 3, "users:write"), codes.OK},
+		{"empty permission", checkPermission(serviceClient(), 3, ""), codes.InvalidArgument},
+		{"expired token checks", checkPermission(bearer("expired"), 1, "users:write"), codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.err); got != tt.want {
+				t.Errorf("code = %v (%v), want %v", got, tt.err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUsersByIDsReportsMissingIDs(t *testing.T) {
+	api := serve(t, fakeAccounts{}, fakeClients{})
+
+	response, err := api.GetUsersByIDs(serviceClient(), &authv1.GetUsersByIDsRequest{Ids: []uint64{1, 99, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.GetUsers()) != 2 || len(response.GetMissingIds()) != 1 || response.GetMissingIds()[0] != 99 {
+		t.Errorf("response = %v, want users 1 and 3 and 99 missing", response)
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	api := serve(t, fakeAccounts{}, fakeClients{})
+
+	tests := []struct {
+		token   string
+		active  bool
+		revoked bool
+		userID  uint64
+	}{
+		{"member", true, false, 1},
+		{"expired", false, false, 0},
+		{"revoked", false, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			response, err := api.ValidateToken(serviceClient(), &authv1.ValidateTokenRequest{Token: tt.token})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.GetActive() != tt.active || response.GetRevoked() != tt.revoked || response.GetUserId() != tt.userID {
+				t.Errorf("response = %v, want active %v, revoked %v, user %d", response, tt.active, tt.revoked, tt.userID)
+			}
+		})
+	}
+
+	if _, err := api.ValidateToken(serviceClient(), &authv1.ValidateTokenRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty token error = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"context"
+	"micro/internal/metrics"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// timeout serves each unary RPC with a context that expires after timeout,
+// or earlier when the caller set a shorter deadline, as middleware.Timeout
+// does for HTTP requests. Streams, such as health watches, are long-lived
+// and keep the deadline of their caller.
+func timeout(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// observe records the duration and status code of each unary RPC, including
+// the ones rejected by authentication.
+func observe(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.RPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"micro/internal/apperr"
+	"micro/internal/audit"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ListAuditLogs(c *fiber.Ctx) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return err
+	}
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 64)
+		if err != nil {
+			return apperr.New(apperr.Invalid, errInvalidFilter.Code, "Invalid actor_id")
+		}
+		filter.ActorID = audit.UserID(uint(id))
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			return apperr.New(apperr.Invalid, errInvalidFilter.Code, "Invalid user_id")
+		}
+		filter.TargetUserID = audit.UserID(uint(id))
+	}
+
+	return h.auditLogPage(c, filter)
+}
+
+func (h *Handler) SecurityActivity(c *fiber.Ctx) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return err
+	}
+
+	userID := middleware.Claims(c).UserID
+	filter.InvolvedUserID = &userID
+
+	return h.auditLogPage(c, filter)
+}
+
+func (h *Handler) auditLogPage(c *fiber.Ctx, filter audit.Filter) error {
+	entries, total, err := h.Audit.List(c.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.AuditLogResponse, 0, len(entries))
+	for i := range entries {
+		responses = append(responses, auditLogResponse(&entries[i]))
+	}
+
+	return c.JSON(request.AuditLogPageResponse{
+		Data: responses,
+		Meta: request.PageMeta{Page: filter.Page, PerPage: filter.PerPage, Total: total},
+	})
+}
+
+// parseAuditFilter reads the filters shared by the admin and per-user audit
+// endpoints: action, outcome, from, to (RFC 3339), page and per_page.
+func parseAuditFilter(c *fiber.Ctx) (audit.Filter, error) {
+	filter := audit.Filter{
+		Action:  c.Query("action"),
+		Outcome: c.Query("outcome"),
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("per_page"),
+	}.WithDefaults()
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, apperr.New(apperr.Invalid, errInvalidFilter.Code, "from must be an RFC 3339 time")
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, apperr.New(apperr.Invalid, errInvalidFilter.Code, "to must be an RFC 3339 time")
+		}
+		filter.To = t
+	}
+
+	return filter, nil
+}
+
+func auditLogResponse(entry *entity.AuditLogs) request.AuditLogResponse {
+	response := request.AuditLogResponse{
+		ID:           entry.ID,
+		ActorID:      entry.ActorID,
+		TargetUserID: entry.TargetUserID,
+		Action:       entry.Action,
+		Outcome:      entry.Outcome,
+		IP:           entry.IP,
+		UserAgent:    entry.UserAgent,
+		CreatedAt:    entry.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if entry.Metadata != "" && json.Valid([]byte(entry.Metadata)) {
+		response.Metadata = json.RawMessage(entry.Metadata)
+	}
+	return response
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"micro/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListAuditLogs(t *testing.T) {
+	a := newTestApp(t, nil)
+	admin := a.createUser(t, "admin@example.com", "secret123")
+	a.store.users[admin.ID].Role = "admin"
+	token := a.login(t, "admin@example.com", "secret123")
+	for i := 0; i < 25; i++ {
+		a.auditor.events = append(a.auditor.events, audit.Event{Action: audit.ActionLogin})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs?actor_id=4&user_id=5&action=auth.login&outcome=failure&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&page=2&per_page=10", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, body := a.do(t, req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", resp.StatusCode, body)
+	}
+
+	filter := a.auditor.filters[len(a.auditor.filters)-1]
+	if *filter.ActorID != 4 || *filter.TargetUserID != 5 || filter.Action != "auth.login" || filter.Outcome != "failure" ||
+		!filter.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !filter.To.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("filter = %+v", filter)
+	}
+	meta, _ := body["meta"].(map[string]interface{})
+	if data, _ := body["data"].([]interface{}); len(data) != 10 || meta["page"] != float64(2) || meta["per_page"] != float64(10) || meta["total"] != float64(26) {
+		t.Errorf("page = %d entries, meta %v; want 10 entries of page 2", len(data), meta)
+	}
+
+	for _, query := range []string{"actor_id=me", "from=yesterday", "to=2024-02-01"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if resp, body := a.do(t, req); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s = %d %v, want 400", query, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestSecurityActivityIsScopedToTheUser(t *testing.T) {
+	a := newTestApp(t, nil)
+	user := a.createUser(t, "jane@example.com", "secret123")
+	token := a.login(t, "jane@example.com", "secret123")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/security-activity?action=auth.login", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, body := a.do(t, req); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", resp.StatusCode, body)
+	}
+
+	filter := a.auditor.filters[len(a.auditor.filters)-1]
+	if filter.InvolvedUserID == nil || *filter.InvolvedUserID != user.ID || filter.Action != "auth.login" {
+		t.Errorf("filter = %+v, want the user's own login entries", filter)
+	}
+}
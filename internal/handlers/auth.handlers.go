@@ -1,323 +1,303 @@
 package handlers
 
 import (
-	"context"
 	"errors"
 	"fmt"
+	"micro/internal/apperr"
+	"micro/internal/audit"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
 	"micro/internal/models/request"
-	"micro/internal/provider"
+	"micro/internal/repository"
 	"micro/internal/services"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func Login(c *fiber.Ctx) error {
+func (h *Handler) Login(c *fiber.Ctx) error {
 	loginRequest := new(request.LoginRequest)
-	if err := c.BodyParser(loginRequest); err != nil {
+	if err := parseBody(c, loginRequest); err != nil {
 		return err
 	}
 
-	if errValidate := services.ValidateLogin(loginRequest); errValidate != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Validation failed",
-			"error":   errValidate.Error(),
-		})
+	if err := services.ValidateLogin(loginRequest); err != nil {
+		return err
 	}
 
-	user, err := services.AuthenticateUser(loginRequest.Email, loginRequest.Password)
+	user, err := h.Auth.AuthenticateUser(c.UserContext(), loginRequest.Email, loginRequest.Password)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Invalid email or password",
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			h.Metrics.Login(false, "error")
+			return err
+		}
+		h.Metrics.Login(false, "invalid_credentials")
+		h.Audit.Record(c, audit.Event{
+			Action:   audit.ActionLogin,
+			Outcome:  audit.OutcomeFailure,
+			Metadata: map[string]interface{}{"email": loginRequest.Email, "reason": "invalid_credentials"},
 		})
+		return err
 	}
 
 	if !user.Verify {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Account not verified. Please check your email for verification instructions.",
+		h.Metrics.Login(false, "not_verified")
+		h.Audit.Record(c, audit.Event{
+			ActorID:      &user.ID,
+			TargetUserID: &user.ID,
+			Action:       audit.ActionLogin,
+			Outcome:      audit.OutcomeFailure,
+			Metadata:     map[string]interface{}{"reason": "not_verified"},
 		})
+		return errAccountNotVerified
 	}
 
-	token, errGenerateToken := services.GenerateJWTToken(user)
-	if errGenerateToken != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Error generating token",
-		})
+	token, err := h.Auth.IssueToken(c.UserContext(), user, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		h.Metrics.Login(false, "error")
+		return err
 	}
 
-	return c.JSON(fiber.Map{
-		"status": true,
-		"token":  token,
+	h.Metrics.Login(true, "")
+	h.Audit.Record(c, audit.Event{
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Action:       audit.ActionLogin,
 	})
+
+	response, err := h.tokenResponse(c, user, token)
+	if err != nil {
+		return err
+	}
+	return c.JSON(response)
 }
 
-func Register(c *fiber.Ctx) error {
-	registerRequest := new(request.RegisterRequest)
-	if err := c.BodyParser(registerRequest); err != nil {
+// Logout revokes the session of the current token and clears the auth
+// cookies, if any.
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	claims := middleware.Claims(c)
+	if err := h.Auth.RevokeSession(c.UserContext(), claims.UserID, claims.SessionID()); err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return err
 	}
 
-	if errValidate := services.ValidateRegister(registerRequest); errValidate != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Validation failed",
-			"error":   errValidate.Error(),
-		})
+	middleware.ClearAuthCookies(c, h.Cookie)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tokenResponse describes the token issued to user. In cookie mode the token
+// is stored in the auth cookie rather than returned.
+func (h *Handler) tokenResponse(c *fiber.Ctx, user *entity.Users, token string) (request.TokenResponse, error) {
+	response := request.TokenResponse{
+		TokenType: "Bearer",
+		ExpiresIn: int64(services.TokenTTL / time.Second),
+		User:      userResponse(user),
+	}
+	if !h.Cookie.Enabled {
+		response.Token = token
+		return response, nil
 	}
 
-	result, err := services.HashAndStoreUser(registerRequest)
+	csrfToken, err := middleware.SetAuthCookies(c, h.Cookie, token, time.Now().Add(services.TokenTTL))
 	if err != nil {
-		if err.Error() == fmt.Sprintf("user with email %s already exists", registerRequest.Email) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"message": "Email already in use",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to register user",
-		})
+		return response, err
 	}
-
-	return c.JSON(fiber.Map{
-		"status":  result,
-		"message": "Registration successful! Please check your email for the verification code",
-	})
+	response.CSRFToken = csrfToken
+	return response, nil
 }
 
-// Oauth google provider
+// oauthSuccess finishes an OAuth callback. In cookie mode the browser is
+// sent back to the frontend path passed as the OAuth state; otherwise the
+// token response is returned with status.
+func (h *Handler) oauthSuccess(c *fiber.Ctx, status int, user *entity.Users, token string) error {
+	response, err := h.tokenResponse(c, user, token)
+	if err != nil {
+		return err
+	}
 
-func AuthGoogle(c *fiber.Ctx) error {
-	form := c.Query("from", "/")
-	url := services.GetGoogleAuthURL(form)
-	return c.Redirect(url)
+	if h.Cookie.Enabled {
+		return c.Redirect(h.Cookie.RedirectTo + safeRedirectPath(c.Query("state")))
+	}
+	return c.Status(status).JSON(response)
 }
 
-func CallbackAuthGoogle(c *fiber.Ctx) error {
-	code := c.Query("code")
-	if code == "" {
-		return c.Status(401).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Authorization code is missing",
-		})
+func userResponse(user *entity.Users) request.UserResponse {
+	return request.UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Verify:    user.Verify,
+		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Contacts:  request.Contacts{Phone: user.Phone, Bio: user.Bio},
 	}
+}
 
-	token, err := provider.GoogleOauthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		return c.Status(401).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to exchange authorization code for token",
-		})
+// safeRedirectPath only allows local paths so the OAuth state cannot be used
+// as an open redirect.
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
 	}
+	return path
+}
 
-	userInfo, err := services.GetGoogleUserInfo(token)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": fmt.Sprintf("Failed to get user info: %v", err),
-		})
+func (h *Handler) Register(c *fiber.Ctx) error {
+	registerRequest := new(request.RegisterRequest)
+	if err := parseBody(c, registerRequest); err != nil {
+		return err
 	}
 
-	email, emailExists := userInfo["email"].(string)
-	givenName := userInfo["given_name"].(string)
-	familyName := userInfo["family_name"].(string)
-
-	if !emailExists {
-		return c.Status(400).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Email is missing from user info",
-		})
+	if err := services.ValidateRegister(registerRequest); err != nil {
+		return err
 	}
 
-	existingUser, err := services.GetUserByEmail(email)
+	user, err := h.Auth.HashAndStoreUser(c.UserContext(), registerRequest)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if saveErr := services.SaveGoogleUser(givenName, familyName, email); saveErr != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": fmt.Sprintf("Failed to save new user data: %v", saveErr),
-				})
-			}
-			existingUser, err = services.GetUserByEmail(email)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": "Failed to fetch the newly created user",
-				})
-			}
-			jwtToken, err := services.GenerateJWTToken(existingUser)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": "Failed to generate JWT token",
-				})
-			}
-
-			return c.JSON(fiber.Map{
-				"status":  "success",
-				"token":   jwtToken,
-				"message": "Registered with Google successfully",
-			})
-		} else {
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": fmt.Sprintf("Failed to check if user exists: %v", err),
+		h.Metrics.Registration("password", registrationResult(err))
+		if services.IsRegistrationDenied(err) {
+			h.Audit.Record(c, audit.Event{
+				Action:   audit.ActionRegister,
+				Outcome:  audit.OutcomeFailure,
+				Metadata: map[string]interface{}{"email": registerRequest.Email, "reason": apperr.Code(err)},
 			})
 		}
+		return err
 	}
 
-	if existingUser.Provider != nil && *existingUser.Provider != "google" {
-		return c.Status(400).JSON(fiber.Map{
-			"status":  "error",
-      "provider" : existingUser.Provider,
-			"message": fmt.Sprintf("Your account is already registered with provider '%s'", *existingUser.Provider),
-		})
-	}
-
-	jwtToken, err := services.GenerateJWTToken(existingUser)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to generate JWT token",
-		})
-	}
+	h.Metrics.Registration("password", "success")
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionRegister,
+		Metadata: map[string]interface{}{"email": registerRequest.Email},
+	})
 
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "User already exists",
-		"token":   jwtToken,
-		"data": fiber.Map{
-			"user": request.UserResponse{
-				ID:        existingUser.ID,
-				Name:      existingUser.Name,
-				FirstName: existingUser.FirstName,
-				LastName:  existingUser.LastName,
-				Email:     existingUser.Email,
-				CreatedAt: existingUser.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-				UpdatedAt: existingUser.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			},
-		},
+	return c.Status(fiber.StatusCreated).JSON(request.RegisterResponse{
+		User: userResponse(user),
 	})
 }
 
+// Oauth google provider
+
+func (h *Handler) AuthGoogle(c *fiber.Ctx) error {
+	return h.oauthRedirect(c, "google")
+}
+
+func (h *Handler) CallbackAuthGoogle(c *fiber.Ctx) error {
+	return h.oauthCallback(c, "google")
+}
+
 // GITHU PROVIDER
 
-func AuthGithub(c *fiber.Ctx) error {
+func (h *Handler) AuthGithub(c *fiber.Ctx) error {
+	return h.oauthRedirect(c, "github")
+}
+
+func (h *Handler) CallbackAuthGithub(c *fiber.Ctx) error {
+	return h.oauthCallback(c, "github")
+}
+
+func (h *Handler) oauthRedirect(c *fiber.Ctx, providerName string) error {
 	form := c.Query("from", "/")
-	url := services.GetGithubAuthUrl(form)
+	url, err := h.Auth.AuthURL(providerName, form)
+	if err != nil {
+		return err
+	}
 	return c.Redirect(url)
 }
 
-func CallbackAuthGithub(c *fiber.Ctx) error {
+func (h *Handler) oauthCallback(c *fiber.Ctx, providerName string) error {
 	code := c.Query("code")
 	if code == "" {
-		return c.Status(401).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Authorization code is missing",
-		})
+		h.Metrics.OAuthCallback(providerName, "missing_code")
+		return errCodeMissing
 	}
 
-	token, err := provider.GithubOauthConfig.Exchange(context.Background(), code)
+	user, created, err := h.Auth.OAuthLogin(c.UserContext(), providerName, code)
 	if err != nil {
-		fmt.Printf("Error exchanging code for token: %v\n", err)
-		return c.Status(401).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to exchange authorization code for token",
-		})
+		h.Metrics.OAuthCallback(providerName, oauthResult(err))
+		if services.IsRegistrationDenied(err) {
+			h.Metrics.Registration(providerName, registrationResult(err))
+		}
+		switch {
+		case services.IsRegistrationDenied(err):
+			h.Audit.Record(c, audit.Event{
+				Action:   audit.ActionOAuthLink,
+				Outcome:  audit.OutcomeFailure,
+				Metadata: map[string]interface{}{"provider": providerName, "reason": apperr.Code(err)},
+			})
+		case errors.Is(err, services.ErrProviderMismatch):
+			h.Audit.Record(c, audit.Event{
+				ActorID:      &user.ID,
+				TargetUserID: &user.ID,
+				Action:       audit.ActionOAuthLogin,
+				Outcome:      audit.OutcomeFailure,
+				Metadata:     map[string]interface{}{"provider": providerName, "reason": "provider_mismatch"},
+			})
+			return apperr.New(apperr.Conflict, services.ErrProviderMismatch.Code,
+				fmt.Sprintf("Your account is already registered with provider '%s'", *user.Provider))
+		}
+		return err
 	}
 
-	userInfo, err := services.GetGithubUserInfo(token)
+	jwtToken, err := h.Auth.IssueToken(c.UserContext(), user, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": fmt.Sprintf("Failed to get user info: %v", err),
-		})
+		h.Metrics.OAuthCallback(providerName, "error")
+		return err
 	}
 
-	email, err := services.GetGithubUserPrimaryEmail(token)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": fmt.Sprintf("Failed to get user email: %v", err),
+	if created {
+		h.Metrics.OAuthCallback(providerName, "registered")
+		h.Metrics.Registration(providerName, "success")
+		h.Audit.Record(c, audit.Event{
+			ActorID:      &user.ID,
+			TargetUserID: &user.ID,
+			Action:       audit.ActionOAuthLink,
+			Metadata:     map[string]interface{}{"provider": providerName},
 		})
-	}
 
-	existingUser, err := services.GetUserByEmail(email)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-
-			var firstName, lastName string
-			if name, ok := userInfo["name"].(string); ok {
-				nameParts := strings.Fields(name)
-				if len(nameParts) > 0 {
-					firstName = nameParts[0]
-					if len(nameParts) > 1 {
-						lastName = strings.Join(nameParts[1:], " ")
-					}
-				}
-			}
-			if saveErr := services.SaveGithubUser(firstName, lastName, email); saveErr != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": fmt.Sprintf("Failed to save new user data: %v", saveErr),
-				})
-			}
-			existingUser, err = services.GetUserByEmail(email)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": "Failed to fetch the newly created user",
-				})
-			}
-			jwtToken, err := services.GenerateJWTToken(existingUser)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"status":  "error",
-					"message": "Failed to generate JWT token",
-				})
-			}
-
-			return c.JSON(fiber.Map{
-				"status":  "success",
-				"token":   jwtToken,
-				"message": "Registered with Github successfully",
-			})
-		} else {
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": fmt.Sprintf("Failed to check if user exists: %v", err),
-			})
-		}
+		return h.oauthSuccess(c, fiber.StatusCreated, user, jwtToken)
 	}
 
-	if existingUser.Provider != nil && *existingUser.Provider != "github" {
-		return c.Status(400).JSON(fiber.Map{
-			"status":  "error",
-      "provider" : existingUser.Provider,
-			"message": fmt.Sprintf("Your account is already registered with provider '%s'", *existingUser.Provider),
-		})
-	}
+	h.Metrics.OAuthCallback(providerName, "success")
+	h.Audit.Record(c, audit.Event{
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Action:       audit.ActionOAuthLogin,
+		Metadata:     map[string]interface{}{"provider": providerName},
+	})
 
-	jwtToken, err := services.GenerateJWTToken(existingUser)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to generate JWT token",
-		})
+	return h.oauthSuccess(c, fiber.StatusOK, user, jwtToken)
+}
+
+// registrationResult names the outcome of a failed registration for the
+// metrics.
+func registrationResult(err error) string {
+	switch {
+	case services.IsRegistrationDenied(err):
+		return "denied"
+	case errors.Is(err, services.ErrUserAlreadyExists):
+		return "conflict"
+	case errors.Is(err, services.ErrInvitationInvalid):
+		return "invalid_invitation"
 	}
+	return "error"
+}
 
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "User already exists",
-		"token":   jwtToken,
-		"data": fiber.Map{
-			"user": request.UserResponse{
-				ID:        existingUser.ID,
-				Name:      existingUser.Name,
-				FirstName: existingUser.FirstName,
-				LastName:  existingUser.LastName,
-				Email:     existingUser.Email,
-				CreatedAt: existingUser.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-				UpdatedAt: existingUser.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			},
-		},
-	})
+// oauthResult names the outcome of a failed OAuth callback for the metrics.
+func oauthResult(err error) string {
+	switch {
+	case errors.Is(err, services.ErrOAuthExchange):
+		return "exchange_failed"
+	case errors.Is(err, services.ErrOAuthEmailMissing):
+		return "email_missing"
+	case services.IsRegistrationDenied(err):
+		return "denied"
+	case errors.Is(err, services.ErrProviderMismatch):
+		return "provider_mismatch"
+	}
+	return "error"
 }
@@ -0,0 +1,291 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"micro/config"
+	"micro/internal/audit"
+	"micro/internal/events"
+	"micro/internal/handlers"
+	"micro/internal/logging"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/routes"
+	"micro/internal/services"
+	"micro/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type testApp struct {
+	app     *fiber.App
+	handler *handlers.Handler
+	store   *fakeStore
+	auditor *fakeAuditor
+}
+
+func newTestApp(t *testing.T, configure func(cfg *config.Config)) *testApp {
+	t.Helper()
+
+	cfg := config.Default()
+	if configure != nil {
+		configure(cfg)
+	}
+
+	store := newFakeStore()
+	auditor := &fakeAuditor{}
+	jwt := utils.NewJWT(cfg.JWT)
+	auth := services.NewAuthService(store, jwt, cfg, logging.Discard(), nil)
+
+	h := &handlers.Handler{Auth: auth, JWT: jwt, Audit: auditor, Cookie: cfg.Cookie}
+	mw := middleware.New(auth, cfg.Cookie, logging.Discard())
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	routes.APIRoutes(app, h, mw)
+
+	return &testApp{app: app, handler: h, store: store, auditor: auditor}
+}
+
+func (a *testApp) createUser(t *testing.T, email, password string) *entity.Users {
+	t.Helper()
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entity.Users{Name: "Jane Doe", Email: email, Password: hashed, Role: "member", Verify: true}
+	if err := a.store.Users().Create(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func (a *testApp) do(t *testing.T, req *http.Request) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	resp, err := a.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var decoded map[string]interface{}
+	json.Unmarshal(body, &decoded)
+	return resp, decoded
+}
+
+func jsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func (a *testApp) login(t *testing.T, email, password string) string {
+	t.Helper()
+	resp, body := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/login", `{"email":"`+email+`","password":"`+password+`"}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d, body = %v", resp.StatusCode, body)
+	}
+	token, _ := body["token"].(string)
+	return token
+}
+
+func TestLogin(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "jane@example.com", "secret123")
+
+	if token := a.login(t, "jane@example.com", "secret123"); token == "" {
+		t.Fatal("login returned no token")
+	}
+	if got := a.auditor.actions(); len(got) != 1 || got[0] != audit.ActionLogin {
+		t.Errorf("audit actions = %v, want [%s]", got, audit.ActionLogin)
+	}
+}
+
+func TestLoginTokenResponse(t *testing.T) {
+	a := newTestApp(t, nil)
+	jane := a.createUser(t, "jane@example.com", "secret123")
+	phone := "+15550100"
+	a.store.users[jane.ID].Phone = &phone
+
+	resp, body := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/login", `{"email":"jane@example.com","password":"secret123"}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if body["token_type"] != "Bearer" || body["expires_in"] != services.TokenTTL.Seconds() {
+		t.Errorf("token_type, expires_in = %v, %v", body["token_type"], body["expires_in"])
+	}
+	if user, _ := body["user"].(map[string]interface{}); user["email"] != "jane@example.com" {
+		t.Errorf("user = %v", body["user"])
+	}
+	if user, _ := body["user"].(map[string]interface{}); !reflect.DeepEqual(user["contacts"], map[string]interface{}{"phone": phone, "bio": nil}) {
+		t.Errorf("user contacts = %v", user["contacts"])
+	}
+	if _, ok := body["refresh_token"]; ok {
+		t.Error("refresh_token returned although none is issued")
+	}
+	if resp.Header.Get("Deprecation") != "" {
+		t.Error("/api/v1 response is marked deprecated")
+	}
+
+	resp, body = a.do(t, jsonRequest(http.MethodPost, "/api/auth/login", `{"email":"jane@example.com","password":"secret123"}`))
+	if resp.StatusCode != http.StatusOK || body["token"] == nil {
+		t.Fatalf("unversioned login = %d %v", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Deprecation") == "" || !strings.Contains(resp.Header.Get("Link"), "/api/v1") {
+		t.Errorf("unversioned response headers = %v, want a deprecation pointing to /api/v1", resp.Header)
+	}
+}
+
+func TestLoginInvalidPassword(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "jane@example.com", "secret123")
+
+	resp, _ := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/login", `{"email":"jane@example.com","password":"wrong"}`))
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", resp.StatusCode)
+	}
+	if a.auditor.events[0].Outcome != audit.OutcomeFailure {
+		t.Errorf("login failure was not audited as a failure")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	a := newTestApp(t, nil)
+	body := `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"secret123"}`
+
+	resp, _ := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/register", body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", resp.StatusCode)
+	}
+	if len(a.store.events) != 1 || a.store.events[0] != events.UserRegistered {
+		t.Errorf("events = %v, want [%s]", a.store.events, events.UserRegistered)
+	}
+
+	resp, _ = a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/register", body))
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("second registration status = %d, want 409", resp.StatusCode)
+	}
+}
+
+func TestRegisterClosed(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) {
+		cfg.Registration.Mode = config.RegistrationClosed
+	})
+
+	resp, _ := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/register",
+		`{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"secret123"}`))
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "jane@example.com", "secret123")
+	token := a.login(t, "jane@example.com", "secret123")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, body := a.do(t, req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("sessions status = %d, want 200", resp.StatusCode)
+	}
+	if sessions, _ := body["data"].([]interface{}); len(sessions) != 1 {
+		t.Fatalf("sessions = %v, want one", body["data"])
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("x-token", token)
+	if resp, _ := a.do(t, req); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("logout status = %d, want 204", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, _ := a.do(t, req); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status after logout = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestCookieModeRequiresCSRFToken(t *testing.T) {
+	a := newTestApp(t, func(cfg *config.Config) {
+		cfg.Cookie.Enabled = true
+	})
+	a.createUser(t, "jane@example.com", "secret123")
+
+	resp, body := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/login", `{"email":"jane@example.com","password":"secret123"}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d", resp.StatusCode)
+	}
+	if _, ok := body["token"]; ok {
+		t.Error("cookie mode login exposed the token in the body")
+	}
+	csrfToken, _ := body["csrf_token"].(string)
+
+	logout := func(withCSRF bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		for _, cookie := range resp.Cookies() {
+			req.AddCookie(cookie)
+		}
+		if withCSRF {
+			req.Header.Set(middleware.HeaderCSRFToken, csrfToken)
+		}
+		r, _ := a.do(t, req)
+		return r.StatusCode
+	}
+
+	if status := logout(false); status != http.StatusForbidden {
+		t.Fatalf("logout without CSRF token status = %d, want 403", status)
+	}
+	if status := logout(true); status != http.StatusNoContent {
+		t.Fatalf("logout with CSRF token status = %d, want 204", status)
+	}
+}
+
+func TestErrorsAreProblemDocuments(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "jane@example.com", "secret123")
+
+	resp, body := a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/register",
+		`{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"secret123"}`))
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != middleware.ContentTypeProblem {
+		t.Errorf("Content-Type = %q, want %q", ct, middleware.ContentTypeProblem)
+	}
+	if body["code"] != "email_taken" || body["status"] != float64(http.StatusConflict) || body["instance"] != "/api/v1/auth/register" {
+		t.Errorf("problem = %v", body)
+	}
+
+	resp, body = a.do(t, jsonRequest(http.MethodPost, "/api/v1/auth/login", `{"email":"not an email"}`))
+	if resp.StatusCode != http.StatusBadRequest || body["code"] != "validation_failed" {
+		t.Fatalf("validation problem = %d %v", resp.StatusCode, body)
+	}
+	if fields, _ := body["errors"].([]interface{}); len(fields) != 2 {
+		t.Errorf("errors = %v, want the email and password fields", body["errors"])
+	}
+}
+
+func TestValidationErrorsFollowAcceptLanguage(t *testing.T) {
+	a := newTestApp(t, nil)
+
+	req := jsonRequest(http.MethodPost, "/api/v1/auth/register",
+		`{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"short"}`)
+	req.Header.Set("Accept-Language", "id-ID,en;q=0.5")
+	resp, body := a.do(t, req)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	fields, _ := body["errors"].([]interface{})
+	if len(fields) != 1 {
+		t.Fatalf("errors = %v, want the password field", body["errors"])
+	}
+	field, _ := fields[0].(map[string]interface{})
+	if field["field"] != "password" || field["rule"] != "password" || !strings.HasPrefix(field["message"].(string), "password harus") {
+		t.Errorf("error = %v, want an Indonesian password message", field)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"micro/internal/apperr"
+	"micro/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	errInvalidBody        = apperr.New(apperr.Invalid, "invalid_body", "The request body could not be parsed")
+	errInvalidID          = apperr.New(apperr.Invalid, "invalid_id", "The id in the path is invalid")
+	errInvalidFilter      = apperr.New(apperr.Invalid, "invalid_filter", "Invalid filter")
+	errAccountNotVerified = apperr.New(apperr.Forbidden, "account_not_verified", "Account not verified. Please check your email for verification instructions.")
+	errCodeMissing        = apperr.New(apperr.Unauthenticated, "authorization_code_missing", "Authorization code is missing")
+
+	errUserNotFound          = apperr.New(apperr.NotFound, "user_not_found", "User not found")
+	errSessionNotFound       = apperr.New(apperr.NotFound, "session_not_found", "Session not found")
+	errInvitationNotFound    = apperr.New(apperr.NotFound, "invitation_not_found", "Invitation not found")
+	errServiceClientNotFound = apperr.New(apperr.NotFound, "service_client_not_found", "Service client not found")
+	errWebhookNotFound       = apperr.New(apperr.NotFound, "webhook_not_found", "Webhook not found")
+	errDeliveryNotFound      = apperr.New(apperr.NotFound, "delivery_not_found", "Delivery not found")
+)
+
+// parseBody decodes the request body into out.
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return errInvalidBody
+	}
+	return nil
+}
+
+// notFound replaces a failed lookup with the not found error of the
+// resource, and returns any other error unchanged.
+func notFound(err, resourceErr error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return resourceErr
+	}
+	return err
+}
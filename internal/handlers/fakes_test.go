@@ -0,0 +1,419 @@
+package handlers_test
+
+import (
+	"context"
+	"micro/internal/audit"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/webhooks"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeStore is an in-memory repository.Store. Transactions are not rolled
+// back, which the handlers under test never rely on.
+type fakeStore struct {
+	mu          sync.Mutex
+	users       map[uint]*entity.Users
+	sessions    map[string]*entity.Sessions
+	invitations map[uint]*entity.Invitations
+	events      []events.Type
+	nextID      uint
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:       map[uint]*entity.Users{},
+		sessions:    map[string]*entity.Sessions{},
+		invitations: map[uint]*entity.Invitations{},
+	}
+}
+
+func (s *fakeStore) Users() repository.UserRepository             { return fakeUsers{s} }
+func (s *fakeStore) Sessions() repository.SessionRepository       { return fakeSessions{s} }
+func (s *fakeStore) Invitations() repository.InvitationRepository { return fakeInvitations{s} }
+
+// The remaining repositories are not used by the handlers, which reach them
+// through the Handler interfaces instead.
+func (s *fakeStore) ServiceClients() repository.ServiceClientRepository      { return nil }
+func (s *fakeStore) AuditLogs() repository.AuditLogRepository                { return nil }
+func (s *fakeStore) WebhookEndpoints() repository.WebhookEndpointRepository  { return nil }
+func (s *fakeStore) WebhookDeliveries() repository.WebhookDeliveryRepository { return nil }
+func (s *fakeStore) SigningKeys() repository.SigningKeyRepository            { return nil }
+
+func (s *fakeStore) Emit(eventType events.Type, userID uint, payload interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, eventType)
+	return nil
+}
+
+func (s *fakeStore) Transaction(fn func(tx repository.Store) error) error {
+	return fn(s)
+}
+
+func (s *fakeStore) WithContext(ctx context.Context) repository.Store {
+	return s
+}
+
+func (s *fakeStore) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+type fakeUsers struct{ s *fakeStore }
+
+func (r fakeUsers) FindByID(id uint) (*entity.Users, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, repository.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r fakeUsers) FindByEmail(email string) (*entity.Users, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, user := range r.s.users {
+		if strings.EqualFold(user.Email, email) && !user.DeletedAt.Valid {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r fakeUsers) FindByIDs(ids []uint) ([]entity.Users, error) {
+	var users []entity.Users
+	for _, id := range ids {
+		if user, err := r.FindByID(id); err == nil {
+			users = append(users, *user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r fakeUsers) List() ([]entity.Users, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var users []entity.Users
+	for _, user := range r.s.users {
+		if !user.DeletedAt.Valid {
+			users = append(users, *user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r fakeUsers) Create(user *entity.Users) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user.ID = r.s.id()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	copied := *user
+	r.s.users[user.ID] = &copied
+	return nil
+}
+
+func (r fakeUsers) Save(user *entity.Users) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	copied := *user
+	r.s.users[user.ID] = &copied
+	return nil
+}
+
+func (r fakeUsers) UpdateRole(id uint, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.users[id].Role = role
+	return nil
+}
+
+func (r fakeUsers) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.users[id].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r fakeUsers) ListDeletedBefore(cutoff time.Time) ([]uint, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ids []uint
+	for id, user := range r.s.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r fakeUsers) Purge(ids []uint) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range ids {
+		delete(r.s.users, id)
+	}
+	return int64(len(ids)), nil
+}
+
+type fakeSessions struct{ s *fakeStore }
+
+func (r fakeSessions) Create(session *entity.Sessions) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session.CreatedAt = time.Now()
+	copied := *session
+	r.s.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r fakeSessions) FindForUser(id string, userID uint) (*entity.Sessions, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session, ok := r.s.sessions[id]
+	if !ok || session.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r fakeSessions) ListActive(userID uint, now time.Time) ([]entity.Sessions, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var sessions []entity.Sessions
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r fakeSessions) ListAll(userID uint) ([]entity.Sessions, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var sessions []entity.Sessions
+	for _, session := range r.s.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r fakeSessions) Revoke(userID uint, id string, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session, ok := r.s.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	session.RevokedAt = &at
+	return nil
+}
+
+func (r fakeSessions) RevokeAll(userID uint, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r fakeSessions) Touch(id string, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if session, ok := r.s.sessions[id]; ok {
+		session.LastSeenAt = at
+	}
+	return nil
+}
+
+func (r fakeSessions) DeleteForUsers(userIDs []uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, session := range r.s.sessions {
+		for _, userID := range userIDs {
+			if session.UserID == userID {
+				delete(r.s.sessions, id)
+			}
+		}
+	}
+	return nil
+}
+
+type fakeInvitations struct{ s *fakeStore }
+
+func (r fakeInvitations) Create(invitation *entity.Invitations) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	invitation.ID = r.s.id()
+	invitation.CreatedAt = time.Now()
+	copied := *invitation
+	r.s.invitations[invitation.ID] = &copied
+	return nil
+}
+
+func (r fakeInvitations) List() ([]entity.Invitations, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var invitations []entity.Invitations
+	for _, invitation := range r.s.invitations {
+		invitations = append(invitations, *invitation)
+	}
+	return invitations, nil
+}
+
+func (r fakeInvitations) FindByTokenHash(tokenHash string) (*entity.Invitations, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, invitation := range r.s.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r fakeInvitations) FindPending(email string, now time.Time) (*entity.Invitations, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, invitation := range r.s.invitations {
+		if invitation.Email == email && invitation.AcceptedAt == nil && invitation.ExpiresAt.After(now) {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r fakeInvitations) MarkAccepted(invitation *entity.Invitations, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	invitation.AcceptedAt = &at
+	r.s.invitations[invitation.ID].AcceptedAt = &at
+	return nil
+}
+
+func (r fakeInvitations) DeletePending(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	invitation, ok := r.s.invitations[id]
+	if !ok || invitation.AcceptedAt != nil {
+		return repository.ErrNotFound
+	}
+	delete(r.s.invitations, id)
+	return nil
+}
+
+// fakeAuditor keeps the recorded events, and the filters it was listed
+// with, in memory.
+type fakeAuditor struct {
+	mu      sync.Mutex
+	events  []audit.Event
+	filters []audit.Filter
+}
+
+func (a *fakeAuditor) Record(c *fiber.Ctx, event audit.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+}
+
+// List pages through the recorded events, ignoring the other filters.
+func (a *fakeAuditor) List(ctx context.Context, filter audit.Filter) ([]entity.AuditLogs, int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.filters = append(a.filters, filter)
+
+	filter = filter.WithDefaults()
+	start := (filter.Page - 1) * filter.PerPage
+	var entries []entity.AuditLogs
+	for i := start; i < len(a.events) && i < start+filter.PerPage; i++ {
+		entries = append(entries, entity.AuditLogs{ID: uint(i + 1), Action: string(a.events[i].Action)})
+	}
+	return entries, int64(len(a.events)), nil
+}
+
+func (a *fakeAuditor) actions() []audit.Action {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	actions := make([]audit.Action, 0, len(a.events))
+	for _, event := range a.events {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+// fakeWebhooks keeps the endpoints in memory, without deliveries.
+type fakeWebhooks struct {
+	mu        sync.Mutex
+	endpoints []entity.WebhookEndpoints
+}
+
+func (w *fakeWebhooks) CreateEndpoint(ctx context.Context, input webhooks.EndpointInput) (*entity.WebhookEndpoints, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	endpoint := entity.WebhookEndpoints{
+		ID:     uint(len(w.endpoints) + 1),
+		URL:    input.URL,
+		Secret: "whsec_test",
+		Events: strings.Join(input.Events, ","),
+		Active: input.Active,
+	}
+	w.endpoints = append(w.endpoints, endpoint)
+	return &endpoint, nil
+}
+
+func (w *fakeWebhooks) ListEndpoints(ctx context.Context) ([]entity.WebhookEndpoints, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]entity.WebhookEndpoints(nil), w.endpoints...), nil
+}
+
+func (w *fakeWebhooks) GetEndpoint(ctx context.Context, id uint) (*entity.WebhookEndpoints, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.endpoints {
+		if w.endpoints[i].ID == id {
+			endpoint := w.endpoints[i]
+			return &endpoint, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (w *fakeWebhooks) UpdateEndpoint(ctx context.Context, id uint, input webhooks.EndpointInput) (*entity.WebhookEndpoints, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (w *fakeWebhooks) DeleteEndpoint(ctx context.Context, id uint) error {
+	return gorm.ErrRecordNotFound
+}
+
+func (w *fakeWebhooks) ListDeliveries(ctx context.Context, endpointID uint, page, perPage int) ([]entity.WebhookDeliveries, int64, error) {
+	return nil, 0, nil
+}
+
+func (w *fakeWebhooks) Redeliver(ctx context.Context, deliveryID uint) (*entity.WebhookDeliveries, error) {
+	return nil, gorm.ErrRecordNotFound
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"micro/config"
+	"micro/internal/audit"
+	"micro/internal/logging"
+	"micro/internal/metrics"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/services"
+	"micro/internal/utils"
+	"micro/internal/webhooks"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Auditor records and lists audit events. *audit.Log implements it.
+type Auditor interface {
+	Record(c *fiber.Ctx, event audit.Event)
+	List(ctx context.Context, filter audit.Filter) ([]entity.AuditLogs, int64, error)
+}
+
+// ServiceClients manages the service clients and answers their token
+// introspection requests. *services.IntrospectionService implements it.
+type ServiceClients interface {
+	CreateServiceClient(ctx context.Context, name string) (*entity.ServiceClients, string, error)
+	ListServiceClients(ctx context.Context) ([]entity.ServiceClients, error)
+	DeleteServiceClient(ctx context.Context, id uint) error
+	AuthenticateServiceClient(ctx context.Context, clientID, secret string) (*entity.ServiceClients, error)
+	IntrospectToken(ctx context.Context, token string) (request.IntrospectionResponse, error)
+}
+
+// WebhookEndpoints manages the webhook endpoints and their delivery log.
+// *webhooks.Service implements it.
+type WebhookEndpoints interface {
+	CreateEndpoint(ctx context.Context, input webhooks.EndpointInput) (*entity.WebhookEndpoints, error)
+	ListEndpoints(ctx context.Context) ([]entity.WebhookEndpoints, error)
+	GetEndpoint(ctx context.Context, id uint) (*entity.WebhookEndpoints, error)
+	UpdateEndpoint(ctx context.Context, id uint, input webhooks.EndpointInput) (*entity.WebhookEndpoints, error)
+	DeleteEndpoint(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, endpointID uint, page, perPage int) ([]entity.WebhookDeliveries, int64, error)
+	Redeliver(ctx context.Context, deliveryID uint) (*entity.WebhookDeliveries, error)
+}
+
+// ReadinessChecker reports the dependencies the service cannot take traffic
+// without, by name. *health.Checker implements it.
+type ReadinessChecker interface {
+	Check(ctx context.Context) map[string]error
+}
+
+// Handler serves the HTTP API on top of the injected services. Handlers only
+// use the fields their routes need, so tests can leave the others nil.
+type Handler struct {
+	Auth          *services.AuthService
+	Introspection ServiceClients
+	Webhooks      WebhookEndpoints
+	JWT           *utils.JWT
+	Audit         Auditor
+	Cookie        config.CookieConfig
+	Health        ReadinessChecker
+	Metrics       *metrics.Metrics
+	Logger        *slog.Logger
+}
+
+// logger returns the request logger of c, falling back to h.Logger.
+func (h *Handler) logger(c *fiber.Ctx) *slog.Logger {
+	fallback := h.Logger
+	if fallback == nil {
+		fallback = logging.Discard()
+	}
+	return logging.FromContext(c.UserContext(), fallback)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Healthz is the liveness probe: it only tells that the process serves
+// requests.
+func (h *Handler) Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// Readyz is the readiness probe: it fails while a dependency is unusable or
+// once shutdown has started. The probe is public, so failing checks are only
+// reported as down; their errors, which may name hosts or credentials, are
+// logged.
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	failures := h.Health.Check(c.UserContext())
+	if len(failures) == 0 {
+		return c.JSON(fiber.Map{
+			"status": "ok",
+		})
+	}
+
+	checks := fiber.Map{}
+	for name, err := range failures {
+		checks[name] = "down"
+		h.logger(c).Warn("readiness check failed", "check", name, "error", err)
+	}
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"status": "unavailable",
+		"checks": checks,
+	})
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"micro/internal/handlers"
+	"micro/internal/health"
+	"micro/internal/routes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestReadyz(t *testing.T) {
+	var dbErr error
+	checker := health.New(time.Second)
+	checker.Add("database", func(context.Context) error { return dbErr })
+
+	app := fiber.New()
+	routes.HealthRoutes(app, &handlers.Handler{Health: checker})
+
+	var body string
+	status := func(path string) int {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		body = string(raw)
+		return resp.StatusCode
+	}
+
+	if got := status("/readyz"); got != fiber.StatusOK {
+		t.Errorf("ready: status = %d, want 200", got)
+	}
+
+	dbErr = errors.New("dial tcp db.internal:5432: connection refused")
+	if got := status("/readyz"); got != fiber.StatusServiceUnavailable {
+		t.Errorf("database down: status = %d, want 503", got)
+	}
+	if !strings.Contains(body, `"database":"down"`) || strings.Contains(body, "db.internal") {
+		t.Errorf("database down: body = %s, want the check down without its error", body)
+	}
+
+	dbErr = nil
+	checker.ShuttingDown()
+	if got := status("/readyz"); got != fiber.StatusServiceUnavailable {
+		t.Errorf("shutting down: status = %d, want 503", got)
+	}
+	if got := status("/healthz"); got != fiber.StatusOK {
+		t.Errorf("healthz while shutting down: status = %d, want 200", got)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"micro/internal/audit"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/services"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) IntrospectToken(c *fiber.Ctx) error {
+	clientID, clientSecret := clientCredentials(c)
+	if _, err := h.Introspection.AuthenticateServiceClient(c.UserContext(), clientID, clientSecret); err != nil {
+		if errors.Is(err, services.ErrInvalidClientCredentials) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="introspection"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "invalid_client",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "server_error",
+		})
+	}
+
+	token := c.FormValue("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":             "invalid_request",
+			"error_description": "token is required",
+		})
+	}
+
+	response, err := h.Introspection.IntrospectToken(c.UserContext(), token)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "server_error",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(response)
+}
+
+// clientCredentials reads client_secret_basic credentials, falling back to
+// client_secret_post form fields.
+func clientCredentials(c *fiber.Ctx) (string, string) {
+	if header := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(header, "Basic ") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+		if err == nil {
+			if id, secret, ok := strings.Cut(string(decoded), ":"); ok {
+				// RFC 6749 section 2.3.1 form-encodes both parts.
+				if unescaped, err := url.QueryUnescape(id); err == nil {
+					id = unescaped
+				}
+				if unescaped, err := url.QueryUnescape(secret); err == nil {
+					secret = unescaped
+				}
+				return id, secret
+			}
+		}
+	}
+	return c.FormValue("client_id"), c.FormValue("client_secret")
+}
+
+func (h *Handler) CreateServiceClient(c *fiber.Ctx) error {
+	clientRequest := new(request.CreateServiceClientRequest)
+	if err := parseBody(c, clientRequest); err != nil {
+		return err
+	}
+
+	if err := services.ValidateCreateServiceClient(clientRequest); err != nil {
+		return err
+	}
+
+	client, secret, err := h.Introspection.CreateServiceClient(c.UserContext(), clientRequest.Name)
+	if err != nil {
+		return err
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionServiceClientCreated,
+		Metadata: map[string]interface{}{"client_id": client.ClientID, "name": client.Name},
+	})
+
+	response := serviceClientResponse(client)
+	response.ClientSecret = secret
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (h *Handler) ListServiceClients(c *fiber.Ctx) error {
+	clients, err := h.Introspection.ListServiceClients(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.ServiceClientResponse, 0, len(clients))
+	for i := range clients {
+		responses = append(responses, serviceClientResponse(&clients[i]))
+	}
+
+	return c.JSON(request.ServiceClientListResponse{Data: responses})
+}
+
+func (h *Handler) DeleteServiceClient(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	if err := h.Introspection.DeleteServiceClient(c.UserContext(), uint(id)); err != nil {
+		return notFound(err, errServiceClientNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionServiceClientDeleted,
+		Metadata: map[string]interface{}{"service_client_id": id},
+	})
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func serviceClientResponse(client *entity.ServiceClients) request.ServiceClientResponse {
+	return request.ServiceClientResponse{
+		ID:        client.ID,
+		Name:      client.Name,
+		ClientID:  client.ClientID,
+		CreatedAt: client.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"micro/internal/audit"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreateInvitation(c *fiber.Ctx) error {
+	invitationRequest := new(request.CreateInvitationRequest)
+	if err := parseBody(c, invitationRequest); err != nil {
+		return err
+	}
+
+	if err := services.ValidateCreateInvitation(invitationRequest); err != nil {
+		return err
+	}
+
+	invitedBy := middleware.Claims(c).UserID
+	invitation, token, err := h.Auth.CreateInvitation(c.UserContext(), invitationRequest, invitedBy)
+	if err != nil {
+		return err
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionInvitationCreated,
+		Metadata: map[string]interface{}{"invitation_id": invitation.ID, "email": invitation.Email, "role": invitation.Role},
+	})
+
+	response := invitationResponse(invitation)
+	response.Token = token
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (h *Handler) ListInvitations(c *fiber.Ctx) error {
+	invitations, err := h.Auth.ListInvitations(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, invitationResponse(&invitations[i]))
+	}
+
+	return c.JSON(request.InvitationListResponse{Data: responses})
+}
+
+func (h *Handler) RevokeInvitation(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	if err := h.Auth.RevokeInvitation(c.UserContext(), uint(id)); err != nil {
+		return notFound(err, errInvitationNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionInvitationRevoked,
+		Metadata: map[string]interface{}{"invitation_id": id},
+	})
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) AcceptInvitation(c *fiber.Ctx) error {
+	acceptRequest := new(request.AcceptInvitationRequest)
+	if err := parseBody(c, acceptRequest); err != nil {
+		return err
+	}
+
+	if err := services.ValidateAcceptInvitation(acceptRequest); err != nil {
+		return err
+	}
+
+	user, err := h.Auth.AcceptInvitation(c.UserContext(), acceptRequest)
+	if err != nil {
+		h.Metrics.Registration("invitation", registrationResult(err))
+		return err
+	}
+
+	h.Metrics.Registration("invitation", "success")
+	h.Audit.Record(c, audit.Event{
+		ActorID:      &user.ID,
+		TargetUserID: &user.ID,
+		Action:       audit.ActionInvitationAccepted,
+		Metadata:     map[string]interface{}{"role": user.Role},
+	})
+
+	token, err := h.Auth.IssueToken(c.UserContext(), user, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return err
+	}
+
+	response, err := h.tokenResponse(c, user, token)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func invitationResponse(invitation *entity.Invitations) request.InvitationResponse {
+	response := request.InvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: invitation.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if invitation.AcceptedAt != nil {
+		acceptedAt := invitation.AcceptedAt.Format("2006-01-02T15:04:05Z07:00")
+		response.AcceptedAt = &acceptedAt
+	}
+	return response
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"math/big"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS publishes the public keys tokens are verified with (RFC 7517).
+func (h *Handler) JWKS(c *fiber.Ctx) error {
+	publicKeys := h.JWT.PublicKeys()
+
+	keys := make([]jsonWebKey, 0, len(publicKeys))
+	for kid, key := range publicKeys {
+		keys = append(keys, jsonWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{"keys": keys})
+}
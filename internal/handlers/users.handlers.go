@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro/internal/apperr"
+	"micro/internal/audit"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/services"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportAuditPageSize is how many audit entries a data export reads at a
+// time; the export includes all of them.
+const exportAuditPageSize = 100
+
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID := middleware.Claims(c).UserID
+	currentSessionID := middleware.Claims(c).SessionID()
+
+	sessions, err := h.Auth.ListUserSessions(c.UserContext(), userID)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, sessionResponse(&session, currentSessionID))
+	}
+
+	return c.JSON(request.SessionListResponse{Data: responses})
+}
+
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID := middleware.Claims(c).UserID
+
+	if err := h.Auth.RevokeSession(c.UserContext(), userID, c.Params("id")); err != nil {
+		return notFound(err, errSessionNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		TargetUserID: &userID,
+		Action:       audit.ActionSessionRevoked,
+		Metadata:     map[string]interface{}{"session_id": c.Params("id")},
+	})
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) DeleteAccount(c *fiber.Ctx) error {
+	deleteRequest := new(request.DeleteAccountRequest)
+	if err := parseBody(c, deleteRequest); err != nil {
+		return err
+	}
+
+	userID := middleware.Claims(c).UserID
+	sessionID := middleware.Claims(c).SessionID()
+
+	if err := h.Auth.DeleteAccount(c.UserContext(), userID, sessionID, deleteRequest.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidPassword) || errors.Is(err, services.ErrReauthenticationRequired) {
+			h.Audit.Record(c, audit.Event{
+				TargetUserID: &userID,
+				Action:       audit.ActionAccountDeleted,
+				Outcome:      audit.OutcomeFailure,
+				Metadata:     map[string]interface{}{"reason": apperr.Code(err)},
+			})
+		}
+		return err
+	}
+
+	h.Audit.Record(c, audit.Event{
+		TargetUserID: &userID,
+		Action:       audit.ActionAccountDeleted,
+	})
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) ExportUserData(c *fiber.Ctx) error {
+	userID := middleware.Claims(c).UserID
+	currentSessionID := middleware.Claims(c).SessionID()
+
+	user, err := h.Auth.GetUserByID(c.UserContext(), userID)
+	if err != nil {
+		return notFound(err, errUserNotFound)
+	}
+
+	sessions, err := h.Auth.ListAllUserSessions(c.UserContext(), userID)
+	if err != nil {
+		return err
+	}
+
+	auditLogs, err := h.allAuditLogs(c.UserContext(), userID)
+	if err != nil {
+		return err
+	}
+
+	provider := "default"
+	if user.Provider != nil {
+		provider = *user.Provider
+	}
+
+	export := request.UserDataExport{
+		ExportedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
+		Profile: request.UserProfile{
+			Name:      user.Name,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Role:      user.Role,
+			Verify:    user.Verify,
+			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Contacts:  request.Contacts{Phone: user.Phone, Bio: user.Bio},
+		},
+		Identities: []request.IdentityResponse{{Provider: provider, Email: user.Email}},
+		Sessions:   make([]request.SessionResponse, 0, len(sessions)),
+		AuditLogs:  make([]request.AuditLogResponse, 0, len(auditLogs)),
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, sessionResponse(&session, currentSessionID))
+	}
+	for i := range auditLogs {
+		export.AuditLogs = append(export.AuditLogs, auditLogResponse(&auditLogs[i]))
+	}
+
+	h.Audit.Record(c, audit.Event{
+		TargetUserID: &userID,
+		Action:       audit.ActionDataExported,
+	})
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="user-%d-export.json"`, user.ID))
+	return c.JSON(export)
+}
+
+func sessionResponse(session *entity.Sessions, currentSessionID string) request.SessionResponse {
+	return request.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Current:    session.ID == currentSessionID,
+		CreatedAt:  session.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		LastSeenAt: session.LastSeenAt.Format("2006-01-02T15:04:05Z07:00"),
+		ExpiresAt:  session.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func (h *Handler) ChangeUserRole(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	changeRoleRequest := new(request.ChangeRoleRequest)
+	if err := parseBody(c, changeRoleRequest); err != nil {
+		return err
+	}
+
+	if err := services.ValidateChangeRole(changeRoleRequest); err != nil {
+		return err
+	}
+
+	targetUserID := uint(id)
+	previousRole, err := h.Auth.ChangeUserRole(c.UserContext(), targetUserID, changeRoleRequest.Role)
+	if err != nil {
+		return notFound(err, errUserNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		TargetUserID: &targetUserID,
+		Action:       audit.ActionRoleChanged,
+		Metadata:     map[string]interface{}{"from": previousRole, "to": changeRoleRequest.Role},
+	})
+
+	return c.JSON(request.ChangeRoleResponse{
+		UserID:       targetUserID,
+		Role:         changeRoleRequest.Role,
+		PreviousRole: previousRole,
+	})
+}
+
+// allAuditLogs returns every audit entry involving userID, newest first.
+func (h *Handler) allAuditLogs(ctx context.Context, userID uint) ([]entity.AuditLogs, error) {
+	var entries []entity.AuditLogs
+	for page := 1; ; page++ {
+		batch, total, err := h.Audit.List(ctx, audit.Filter{InvolvedUserID: &userID, Page: page, PerPage: exportAuditPageSize})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, batch...)
+		if len(batch) < exportAuditPageSize || int64(len(entries)) >= total {
+			return entries, nil
+		}
+	}
+}
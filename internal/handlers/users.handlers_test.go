@@ -0,0 +1,30 @@
+package handlers_test
+
+import (
+	"micro/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExportIncludesAllAuditLogs(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.createUser(t, "jane@example.com", "secret123")
+	token := a.login(t, "jane@example.com", "secret123")
+
+	for i := 0; i < 249; i++ {
+		a.auditor.events = append(a.auditor.events, audit.Event{Action: audit.ActionLogin})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/export", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, body := a.do(t, req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", resp.StatusCode, body)
+	}
+
+	// 250 logins, and the export itself is recorded once it is built.
+	if logs, _ := body["auditLogs"].([]interface{}); len(logs) != 250 {
+		t.Errorf("export has %d audit logs, want 250", len(logs))
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"micro/internal/audit"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/validation"
+	"micro/internal/webhooks"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	input, err := parseWebhookRequest(c)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := h.Webhooks.CreateEndpoint(c.UserContext(), input)
+	if err != nil {
+		return err
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionWebhookCreated,
+		Metadata: map[string]interface{}{"webhook_id": endpoint.ID, "url": endpoint.URL},
+	})
+
+	response := webhookResponse(endpoint)
+	response.Secret = endpoint.Secret
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (h *Handler) ListWebhooks(c *fiber.Ctx) error {
+	endpoints, err := h.Webhooks.ListEndpoints(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.WebhookEndpointResponse, 0, len(endpoints))
+	for i := range endpoints {
+		responses = append(responses, webhookResponse(&endpoints[i]))
+	}
+
+	return c.JSON(request.WebhookEndpointListResponse{Data: responses})
+}
+
+func (h *Handler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	input, err := parseWebhookRequest(c)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := h.Webhooks.UpdateEndpoint(c.UserContext(), uint(id), input)
+	if err != nil {
+		return notFound(err, errWebhookNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionWebhookUpdated,
+		Metadata: map[string]interface{}{"webhook_id": endpoint.ID, "url": endpoint.URL},
+	})
+
+	return c.JSON(webhookResponse(endpoint))
+}
+
+func (h *Handler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	if err := h.Webhooks.DeleteEndpoint(c.UserContext(), uint(id)); err != nil {
+		return notFound(err, errWebhookNotFound)
+	}
+
+	h.Audit.Record(c, audit.Event{
+		Action:   audit.ActionWebhookDeleted,
+		Metadata: map[string]interface{}{"webhook_id": id},
+	})
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) ListWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	if _, err := h.Webhooks.GetEndpoint(c.UserContext(), uint(id)); err != nil {
+		return notFound(err, errWebhookNotFound)
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	deliveries, total, err := h.Webhooks.ListDeliveries(c.UserContext(), uint(id), page, perPage)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]request.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		responses = append(responses, webhookDeliveryResponse(&deliveries[i]))
+	}
+
+	return c.JSON(request.WebhookDeliveryPageResponse{
+		Data: responses,
+		Meta: request.PageMeta{Page: page, PerPage: perPage, Total: total},
+	})
+}
+
+func (h *Handler) RedeliverWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return errInvalidID
+	}
+
+	delivery, err := h.Webhooks.Redeliver(c.UserContext(), uint(id))
+	if err != nil {
+		return notFound(err, errDeliveryNotFound)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(webhookDeliveryResponse(delivery))
+}
+
+// parseWebhookRequest reads and validates the endpoint input of the body.
+func parseWebhookRequest(c *fiber.Ctx) (webhooks.EndpointInput, error) {
+	webhookRequest := new(request.WebhookEndpointRequest)
+	if err := parseBody(c, webhookRequest); err != nil {
+		return webhooks.EndpointInput{}, err
+	}
+
+	if err := validation.Struct(webhookRequest); err != nil {
+		return webhooks.EndpointInput{}, err
+	}
+
+	active := true
+	if webhookRequest.Active != nil {
+		active = *webhookRequest.Active
+	}
+
+	return webhooks.EndpointInput{
+		URL:         webhookRequest.URL,
+		Secret:      webhookRequest.Secret,
+		Events:      webhookRequest.Events,
+		Description: webhookRequest.Description,
+		Active:      active,
+	}, nil
+}
+
+func webhookResponse(endpoint *entity.WebhookEndpoints) request.WebhookEndpointResponse {
+	return request.WebhookEndpointResponse{
+		ID:          endpoint.ID,
+		URL:         endpoint.URL,
+		Events:      strings.Split(endpoint.Events, ","),
+		Description: endpoint.Description,
+		Active:      endpoint.Active,
+		CreatedAt:   endpoint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   endpoint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func webhookDeliveryResponse(delivery *entity.WebhookDeliveries) request.WebhookDeliveryResponse {
+	response := request.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format("2006-01-02T15:04:05Z07:00")
+		response.DeliveredAt = &deliveredAt
+	}
+	return response
+}
@@ -0,0 +1,38 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookEndpoints(t *testing.T) {
+	a := newTestApp(t, nil)
+	a.handler.Webhooks = &fakeWebhooks{}
+	admin := a.createUser(t, "admin@example.com", "secret123")
+	a.store.users[admin.ID].Role = "admin"
+	token := a.login(t, "admin@example.com", "secret123")
+
+	req := jsonRequest(http.MethodPost, "/api/v1/webhooks", `{"url":"https://hooks.example.com","events":["*"]}`)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, body := a.do(t, req)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %v", resp.StatusCode, body)
+	}
+	if body["secret"] != "whsec_test" {
+		t.Errorf("created = %v, want the endpoint with its secret", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, body = a.do(t, req)
+	if endpoints, _ := body["data"].([]interface{}); resp.StatusCode != http.StatusOK || len(endpoints) != 1 {
+		t.Errorf("list = %d %v, want the created endpoint", resp.StatusCode, body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/7/deliveries", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, body := a.do(t, req); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deliveries of an unknown endpoint = %d %v, want 404", resp.StatusCode, body)
+	}
+}
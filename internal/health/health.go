@@ -0,0 +1,80 @@
+// Package health tracks whether the service can take traffic.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown is reported by Check once shutdown has started, so load
+// balancers stop routing new requests while in-flight ones drain.
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports whether one dependency is usable.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker runs the readiness checks of the registered dependencies.
+type Checker struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// New returns a Checker that gives every check at most timeout.
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a dependency check under name.
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// ShuttingDown makes every following Check fail.
+func (c *Checker) ShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check runs the checks concurrently and returns the error of each failing
+// one by name. The service is ready when the map is empty.
+func (c *Checker) Check(ctx context.Context) map[string]error {
+	failures := map[string]error{}
+	if c.shuttingDown.Load() {
+		failures["server"] = ErrShuttingDown
+		return failures
+	}
+
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			if err := chk.fn(ctx); err != nil {
+				mu.Lock()
+				failures[chk.name] = err
+				mu.Unlock()
+			}
+		}(chk)
+	}
+	wg.Wait()
+	return failures
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values in the logs.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as substrings of lowercased attribute keys.
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"dsn",
+	"private_key",
+	"api_key",
+	"csrf",
+}
+
+var sensitiveValues = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// JSON Web Tokens.
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+	// Authorization header values.
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`), "$1 " + Redacted},
+	// Credentials in URLs and DSNs, e.g. mysql "user:pass@tcp(host)".
+	{regexp.MustCompile(`([A-Za-z0-9_.-]+):[^@\s/]+@`), "$1:" + Redacted + "@"},
+	// key=value pairs, e.g. a PostgreSQL DSN or a query string.
+	{regexp.MustCompile(`(?i)\b(password|secret|token|client_secret)=[^\s&]+`), "$1=" + Redacted},
+}
+
+// IsSensitiveKey reports whether an attribute, header or field named key
+// holds a secret.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact masks the secrets found in s.
+func Redact(s string) string {
+	for _, sensitive := range sensitiveValues {
+		s = sensitive.pattern.ReplaceAllString(s, sensitive.replacement)
+	}
+	return s
+}
+
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Redact(err.Error()))
+		}
+	}
+	return attr
+}
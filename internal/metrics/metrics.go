@@ -0,0 +1,219 @@
+// Package metrics exposes the service's Prometheus metrics. Every method is
+// safe on a nil *Metrics, so components built without metrics (tests, the
+// operator CLI) need no special casing.
+package metrics
+
+import (
+	"database/sql"
+	"micro/internal/apperr"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "auth"
+
+// Metrics holds the collectors of one process.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests          *prometheus.HistogramVec
+	grpcRequests          *prometheus.HistogramVec
+	logins                *prometheus.CounterVec
+	registrations         *prometheus.CounterVec
+	oauthCallbacks        *prometheus.CounterVec
+	oauthRequests         *prometheus.HistogramVec
+	tokensIssued          prometheus.Counter
+	tokenVerifyFailures   *prometheus.CounterVec
+	passwordHashDurations *prometheus.HistogramVec
+}
+
+// New creates the collectors, along with the Go runtime and process ones, on
+// a registry of their own.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		grpcRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Duration of unary gRPC calls by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Password logins by result and failure reason.",
+		}, []string{"result", "reason"}),
+		registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "Account registrations by method and result.",
+		}, []string{"method", "result"}),
+		oauthCallbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "oauth_callbacks_total",
+			Help:      "OAuth callbacks by provider and result.",
+		}, []string{"provider", "result"}),
+		oauthRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "oauth_request_duration_seconds",
+			Help:      "Duration of the calls to OAuth providers by operation and outcome.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"provider", "operation", "outcome"}),
+		tokensIssued: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_issued_total",
+			Help:      "Access tokens issued.",
+		}),
+		tokenVerifyFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_verification_failures_total",
+			Help:      "Access tokens rejected, by reason.",
+		}, []string{"reason"}),
+		passwordHashDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "password_hash_duration_seconds",
+			Help:      "Duration of bcrypt hashing and comparisons.",
+			Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.grpcRequests,
+		m.logins,
+		m.registrations,
+		m.oauthCallbacks,
+		m.oauthRequests,
+		m.tokensIssued,
+		m.tokenVerifyFailures,
+		m.passwordHashDurations,
+	)
+	return m
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, "main"))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware observes the duration of every request, labelled with the
+// route template rather than the path so that ids do not explode the
+// number of series.
+func (m *Metrics) Middleware(c *fiber.Ctx) error {
+	if m == nil {
+		return c.Next()
+	}
+
+	start := time.Now()
+	err := c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		status, _ = apperr.Resolve(err)
+	}
+	route := c.Route().Path
+	if status == fiber.StatusNotFound && route == "/" {
+		route = "unmatched"
+	}
+
+	m.httpRequests.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// RPC observes a unary gRPC call to method, the full method name, that
+// ended with the status code named code.
+func (m *Metrics) RPC(method, code string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.grpcRequests.WithLabelValues(method, code).Observe(elapsed.Seconds())
+}
+
+// Login counts a password login. reason is empty on success.
+func (m *Metrics) Login(success bool, reason string) {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(result(success), reason).Inc()
+}
+
+// Registration counts an account creation attempt through method
+// ("password", "invitation" or an OAuth provider name).
+func (m *Metrics) Registration(method, result string) {
+	if m == nil {
+		return
+	}
+	m.registrations.WithLabelValues(method, result).Inc()
+}
+
+// OAuthCallback counts a callback from provider.
+func (m *Metrics) OAuthCallback(provider, result string) {
+	if m == nil {
+		return
+	}
+	m.oauthCallbacks.WithLabelValues(provider, result).Inc()
+}
+
+func (m *Metrics) oauthRequest(provider, operation string, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	m.oauthRequests.WithLabelValues(provider, operation, outcome).Observe(elapsed.Seconds())
+}
+
+// TokenIssued counts an access token handed out.
+func (m *Metrics) TokenIssued() {
+	if m == nil {
+		return
+	}
+	m.tokensIssued.Inc()
+}
+
+// TokenRejected counts an access token that failed verification.
+func (m *Metrics) TokenRejected(reason string) {
+	if m == nil {
+		return
+	}
+	m.tokenVerifyFailures.WithLabelValues(reason).Inc()
+}
+
+// PasswordHash observes a bcrypt operation ("hash" or "compare").
+func (m *Metrics) PasswordHash(operation string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.passwordHashDurations.WithLabelValues(operation).Observe(elapsed.Seconds())
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
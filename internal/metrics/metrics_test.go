@@ -0,0 +1,51 @@
+package metrics_test
+
+import (
+	"io"
+	"micro/internal/metrics"
+	"micro/internal/routes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMiddlewareLabelsRouteTemplate(t *testing.T) {
+	m := metrics.New()
+	app := fiber.New()
+	app.Use(m.Middleware)
+	routes.MetricsRoutes(app, m)
+	app.Get("/users/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil), -1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Login(false, "invalid_credentials")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`auth_http_request_duration_seconds_count{method="GET",route="/users/:id",status="204"} 2`,
+		`auth_logins_total{reason="invalid_credentials",result="failure"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+	if strings.Contains(string(body), `route="/users/1"`) {
+		t.Error("metrics are labelled with the request path")
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *metrics.Metrics
+	m.Login(true, "")
+	m.TokenIssued()
+	m.TokenRejected("expired")
+}
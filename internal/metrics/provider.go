@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"micro/internal/provider"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// instrumentedProvider times the calls made to an OAuth provider.
+type instrumentedProvider struct {
+	provider.Provider
+	metrics *Metrics
+}
+
+// InstrumentProvider returns p observing the duration of its token
+// exchanges and user info requests.
+func (m *Metrics) InstrumentProvider(p provider.Provider) provider.Provider {
+	if m == nil {
+		return p
+	}
+	return &instrumentedProvider{Provider: p, metrics: m}
+}
+
+func (p *instrumentedProvider) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	start := time.Now()
+	token, err := p.Provider.Exchange(ctx, code)
+	p.metrics.oauthRequest(p.Name(), "exchange", err, time.Since(start))
+	return token, err
+}
+
+func (p *instrumentedProvider) UserInfo(ctx context.Context, token *oauth2.Token) (*provider.UserInfo, error) {
+	start := time.Now()
+	info, err := p.Provider.UserInfo(ctx, token)
+	p.metrics.oauthRequest(p.Name(), "user_info", err, time.Since(start))
+	return info, err
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"micro/internal/apperr"
+	"micro/internal/models/request"
+	"micro/internal/validation"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContentTypeProblem is the media type of error responses (RFC 7807).
+const ContentTypeProblem = "application/problem+json"
+
+// ErrorHandler is the Fiber error handler: it answers every error returned
+// by a handler or middleware with a problem document. The text of errors
+// that are not apperr errors is only logged, by AccessLog. Validation
+// errors list the invalid fields, in the language the client accepts.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status, resolved := apperr.Resolve(err)
+
+	return c.Status(status).JSON(request.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    resolved.Message,
+		Code:      resolved.Code,
+		Instance:  c.Path(),
+		RequestID: RequestIDFrom(c),
+		Errors:    validation.Fields(err, c.Get(fiber.HeaderAcceptLanguage)),
+	}, ContentTypeProblem)
+}
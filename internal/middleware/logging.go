@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"log/slog"
+	"micro/internal/apperr"
+	"micro/internal/logging"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries the request ID in requests and responses.
+const HeaderRequestID = "X-Request-ID"
+
+const requestIDKey = "request_id"
+
+// validRequestID bounds what a client may send as a request ID, since it is
+// written to the logs as is.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reuses the X-Request-ID of the request when it is well formed,
+// or generates one, returns it in the response and stores a logger tagged
+// with it in the request context.
+func (m *Middleware) RequestID(c *fiber.Ctx) error {
+	id := c.Get(HeaderRequestID)
+	if !validRequestID.MatchString(id) {
+		id = uuid.NewString()
+	}
+
+	c.Set(HeaderRequestID, id)
+	c.Locals(requestIDKey, id)
+	c.SetUserContext(logging.WithLogger(c.UserContext(), m.logger.With("request_id", id)))
+	return c.Next()
+}
+
+// RequestIDFrom returns the ID assigned by RequestID, or "" when the request
+// did not go through it.
+func RequestIDFrom(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDKey).(string)
+	return id
+}
+
+// AccessLog logs every request once handled. Only the path is logged: query
+// strings may carry OAuth codes and other secrets.
+func (m *Middleware) AccessLog(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	status := responseStatus(c, err)
+
+	level := slog.LevelInfo
+	if status >= fiber.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	attrs := []any{
+		"method", c.Method(),
+		"path", c.Path(),
+		"status", status,
+		"duration", time.Since(start),
+		"ip", c.IP(),
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	logging.FromContext(c.UserContext(), m.logger).Log(c.UserContext(), level, "request", attrs...)
+	return err
+}
+
+// responseStatus is the status the client receives once Fiber's error
+// handler has turned err, if any, into a response.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	status, _ := apperr.Resolve(err)
+	return status
+}
@@ -1,71 +1,99 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
 	"micro/config"
+	"micro/internal/apperr"
+	"micro/internal/logging"
 	"micro/internal/models/entity"
+	"micro/internal/services"
 	"micro/internal/utils"
-	"net/http"
-
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func Auth(c *fiber.Ctx) error {
-	token := c.Get("x-token")
+const (
+	claimsKey = "claims"
+	userKey   = "user"
+)
+
+var (
+	ErrUnauthorized     = apperr.New(apperr.Unauthenticated, "unauthorized", "Authentication is required")
+	ErrInvalidCSRFToken = apperr.New(apperr.Forbidden, "invalid_csrf_token", "Invalid CSRF token")
+	ErrForbidden        = apperr.New(apperr.Forbidden, "forbidden", "Admin access is required")
+)
+
+// TokenValidator resolves access tokens. *services.AuthService implements it.
+type TokenValidator interface {
+	ValidateAccessToken(ctx context.Context, token string) (*services.TokenInfo, error)
+	TouchSession(ctx context.Context, session *entity.Sessions) error
+}
+
+// Middleware authenticates requests with the injected TokenValidator and
+// logs them with the injected logger.
+type Middleware struct {
+	tokens TokenValidator
+	cookie config.CookieConfig
+	logger *slog.Logger
+}
+
+func New(tokens TokenValidator, cookie config.CookieConfig, logger *slog.Logger) *Middleware {
+	return &Middleware{tokens: tokens, cookie: cookie, logger: logger}
+}
+
+func (m *Middleware) Auth(c *fiber.Ctx) error {
+	token, fromCookie := tokenFromRequest(c, m.cookie)
 	if token == "" {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Unauthorized",
-		})
+		return ErrUnauthorized
+	}
+
+	if fromCookie && !validCSRF(c, m.cookie) {
+		return ErrInvalidCSRFToken
 	}
 
-	claims, err := utils.DecodeToken(token)
+	info, err := m.tokens.ValidateAccessToken(c.UserContext(), token)
 	if err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Unauthorized",
-		})
+		return err
 	}
 
-	userID := uint(claims["id"].(float64))
-	var user entity.Users
-	if err := config.DB.First(&user, userID).Error; err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"message": "User not found",
-		})
+	if err := m.tokens.TouchSession(c.UserContext(), info.Session); err != nil {
+		logging.FromContext(c.UserContext(), m.logger).Warn("failed to update session", "session_id", info.Session.ID, "error", err)
 	}
-	//
+
 	// if !user.Verify {
 	// 	return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
 	// 		"message": "Account not verified. Please check your email for verification instructions.",
 	// 	})
 	// }
 
-	c.Locals("usersInfo", claims)
-	c.Locals("role", claims["role"])
+	c.Locals(claimsKey, info.Claims)
+	c.Locals(userKey, info.User)
 	return c.Next()
 }
 
-func AdminRole(c *fiber.Ctx) error {
-	role := c.Locals("role")
-
-	if role == "member" {
-		return c.Status(http.StatusForbidden).JSON(fiber.Map{
-			"message": "forbidden access",
-		})
-	}
+// Claims returns the verified token claims of the request, or nil when it
+// did not go through Auth.
+func Claims(c *fiber.Ctx) *utils.Claims {
+	claims, _ := c.Locals(claimsKey).(*utils.Claims)
+	return claims
+}
 
-	return c.Next()
+// CurrentUser returns the user loaded by Auth, or nil when the request did
+// not go through it.
+func CurrentUser(c *fiber.Ctx) *entity.Users {
+	user, _ := c.Locals(userKey).(*entity.Users)
+	return user
 }
 
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+// AdminRole only lets admins through. It checks the stored role rather than
+// the token's, so a demotion takes effect immediately.
+func AdminRole(c *fiber.Ctx) error {
+	user := CurrentUser(c)
+
+	if user == nil || user.Role != "admin" {
+		return ErrForbidden
 	}
-	return string(hashedPassword), nil
-}
 
-func CheckPassword(hashedPassword, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	return c.Next()
 }
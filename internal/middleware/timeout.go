@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout serves each request with a context that expires after timeout.
+// The services pass it down to every query and provider call, which stop
+// once it expires. Fasthttp does not report a client that disconnects while
+// its handler runs, so this deadline is also what ends the work of a request
+// nobody waits for anymore.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
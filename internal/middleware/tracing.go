@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"micro/internal/logging"
+	"micro/internal/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace continues the trace of the W3C traceparent header, or starts one,
+// with a server span per request. The span is stored in the request context
+// and its trace ID added to the context logger. It goes after RequestID.
+func (m *Middleware) Trace(c *fiber.Ctx) error {
+	headers := propagation.HeaderCarrier{}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		headers.Set(string(key), string(value))
+	})
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headers)
+
+	ctx, span := tracing.Start(ctx, c.Method(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+			attribute.String("http.request_id", RequestIDFrom(c)),
+		))
+	defer span.End()
+
+	if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+		logger := logging.FromContext(ctx, m.logger).With("trace_id", spanContext.TraceID().String())
+		ctx = logging.WithLogger(ctx, logger)
+	}
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	status := responseStatus(c, err)
+	if err != nil {
+		span.RecordError(err)
+	}
+	route := c.Route().Path
+	span.SetName(c.Method() + " " + route)
+	span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, "")
+	}
+	return err
+}
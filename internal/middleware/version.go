@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Deprecated marks the responses of a path prefix deprecated since the given
+// time (RFC 9745) and links to the prefix replacing it.
+func Deprecated(successor string, since time.Time) fiber.Handler {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	link := "<" + successor + `>; rel="successor-version"`
+
+	return func(c *fiber.Ctx) error {
+		// A group's middleware also runs for the unmatched paths below its
+		// prefix, which include the successor's.
+		if !strings.HasPrefix(c.Path(), successor) {
+			c.Set("Deprecation", deprecation)
+			c.Set(fiber.HeaderLink, link)
+		}
+		return c.Next()
+	}
+}
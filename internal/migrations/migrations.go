@@ -0,0 +1,349 @@
+// Package migrations applies the versioned SQL migrations embedded from
+// sql/<driver>/<version>_<name>.{up,down}.sql and records them in the
+// schema_migrations table.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var files embed.FS
+
+// SourceDir is where Create writes new migrations, relative to the module root.
+const SourceDir = "internal/migrations/sql"
+
+const historyTable = "schema_migrations"
+
+var (
+	// ErrPending is returned by Check when the schema is behind the binary.
+	ErrPending = errors.New("database schema has pending migrations")
+	// ErrUnknownVersion is returned by Check when the schema was migrated by
+	// a newer binary.
+	ErrUnknownVersion = errors.New("database schema has migrations unknown to this binary")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema change and its rollback.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied, and when.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type historyEntry struct {
+	Version   uint64 `gorm:"primaryKey"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (historyEntry) TableName() string {
+	return historyTable
+}
+
+// Migrator runs the migrations of one driver against db.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New returns a Migrator for the embedded migrations of driver.
+func New(db *gorm.DB, driver string) (*Migrator, error) {
+	migrations, err := load(files, "sql/"+driver)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (m *Migrator) ensureHistory() error {
+	return m.db.Exec("CREATE TABLE IF NOT EXISTS " + historyTable + " (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"applied_at TIMESTAMP NOT NULL)").Error
+}
+
+func (m *Migrator) applied() (map[uint64]historyEntry, error) {
+	applied := map[uint64]historyEntry{}
+	if !m.db.Migrator().HasTable(historyTable) {
+		return applied, nil
+	}
+	var entries []historyEntry
+	if err := m.db.Order("version").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		applied[entry.Version] = entry
+	}
+	return applied, nil
+}
+
+// Status lists every known migration in order, followed by any applied
+// version this binary does not know about.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if entry, ok := applied[migration.Version]; ok {
+			appliedAt := entry.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, entry := range applied {
+		appliedAt := entry.AppliedAt
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: entry.Version, Name: entry.Name},
+			AppliedAt: &appliedAt,
+		})
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check returns ErrPending when a migration has not been applied and
+// ErrUnknownVersion when the schema is ahead of the binary. It never
+// changes the database.
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	known := map[uint64]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		switch {
+		case !known[status.Version]:
+			return fmt.Errorf("%w: %d_%s", ErrUnknownVersion, status.Version, status.Name)
+		case status.AppliedAt == nil:
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d not applied", ErrPending, pending)
+	}
+	return nil
+}
+
+// Up applies the pending migrations in order, at most limit of them when
+// limit is positive, and returns those it applied.
+func (m *Migrator) Up(limit int) ([]Migration, error) {
+	if err := m.ensureHistory(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if limit > 0 && len(done) == limit {
+			break
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&historyEntry{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns those it rolled back.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return done, fmt.Errorf("migration %d_%s cannot be rolled back", migration.Version, migration.Name)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&historyEntry{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// execScript runs the statements of script one by one, since not every
+// driver accepts several statements in one call. Statements end with a
+// semicolon at the end of a line.
+func execScript(tx *gorm.DB, script string) error {
+	var statement strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if err := tx.Exec(statement.String()).Error; err != nil {
+				return err
+			}
+			statement.Reset()
+		}
+	}
+	if strings.TrimSpace(statement.String()) != "" {
+		return tx.Exec(statement.String()).Error
+	}
+	return nil
+}
+
+// Drivers lists the drivers that have a migrations directory.
+func Drivers() []string {
+	entries, _ := fs.ReadDir(files, "sql")
+	var drivers []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			drivers = append(drivers, entry.Name())
+		}
+	}
+	return drivers
+}
+
+// Create writes empty up and down scripts for every driver under dir, using
+// the next free version, and returns their paths.
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !fileName.MatchString("1_" + name + ".up.sql") {
+		return nil, fmt.Errorf("invalid migration name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	var next uint64 = 1
+	for _, driver := range Drivers() {
+		migrations, err := load(os.DirFS(dir), driver)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version >= next {
+			next = migrations[n-1].Version + 1
+		}
+	}
+
+	var paths []string
+	for _, driver := range Drivers() {
+		if err := os.MkdirAll(filepath.Join(dir, driver), 0o755); err != nil {
+			return paths, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			body := fmt.Sprintf("-- %s: %s (%s)\n", name, direction, driver)
+			if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
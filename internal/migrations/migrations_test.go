@@ -0,0 +1,158 @@
+package migrations_test
+
+import (
+	"errors"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/migrations"
+	"micro/internal/models/entity"
+	"micro/internal/testutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newMigrator(t *testing.T) (*gorm.DB, *migrations.Migrator) {
+	t.Helper()
+	db, err := config.Connect(config.DatabaseConfig{Driver: config.DriverSQLite, DSN: ":memory:"}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(db, config.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, migrator
+}
+
+func TestEveryDriverHasTheSameMigrations(t *testing.T) {
+	var want []migrations.Status
+	for _, driver := range []string{config.DriverMySQL, config.DriverPostgres, config.DriverSQLite} {
+		db, _ := newMigrator(t)
+		migrator, err := migrations.New(db, driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		statuses, err := migrator.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want = statuses
+			continue
+		}
+		if len(statuses) != len(want) {
+			t.Fatalf("%s has %d migrations, want %d", driver, len(statuses), len(want))
+		}
+		for i := range statuses {
+			if statuses[i].Version != want[i].Version || statuses[i].Name != want[i].Name || statuses[i].Down == "" {
+				t.Errorf("%s migration %d_%s does not match %d_%s or has no down script",
+					driver, statuses[i].Version, statuses[i].Name, want[i].Version, want[i].Name)
+			}
+		}
+	}
+}
+
+func TestCheckRefusesUnmigratedSchema(t *testing.T) {
+	_, migrator := newMigrator(t)
+
+	if err := migrator.Check(); !errors.Is(err, migrations.ErrPending) {
+		t.Fatalf("Check() on an empty database = %v, want ErrPending", err)
+	}
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Fatalf("Check() after Up = %v", err)
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	db, migrator := newMigrator(t)
+
+	applied, err := migrator.Up(0)
+	if err != nil || len(applied) == 0 {
+		t.Fatalf("Up = %v, %v", applied, err)
+	}
+	if again, err := migrator.Up(0); err != nil || len(again) != 0 {
+		t.Fatalf("second Up = %v, %v; want nothing to do", again, err)
+	}
+
+	if _, err := migrator.Down(len(applied)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if db.Migrator().HasTable(&entity.Users{}) {
+		t.Error("users table survived rolling back every migration")
+	}
+	if err := migrator.Check(); !errors.Is(err, migrations.ErrPending) {
+		t.Fatalf("Check() after Down = %v, want ErrPending", err)
+	}
+}
+
+// TestSchemaMatchesEntities guards against an entity change without a
+// migration.
+func TestSchemaMatchesEntities(t *testing.T) {
+	db := testutil.OpenDB(t)
+
+	models := []interface{}{
+		&entity.Users{},
+		&entity.Invitations{},
+		&entity.Sessions{},
+		&entity.AuditLogs{},
+		&entity.OutboxMessages{},
+		&entity.WebhookEndpoints{},
+		&entity.WebhookDeliveries{},
+		&entity.ServiceClients{},
+		&entity.SigningKeys{},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		table := stmt.Schema.Table
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s is missing", table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s is missing", table, field.DBName)
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(model, index.Name) {
+				t.Errorf("index %s on %s is missing", index.Name, table)
+			}
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+
+	paths, err := migrations.Create(dir, "Add user locale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2*len(migrations.Drivers()) {
+		t.Fatalf("created %v, want an up and a down script per driver", paths)
+	}
+	if _, err := os.Stat(filepath.Join(dir, config.DriverSQLite, "0001_add_user_locale.up.sql")); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err = migrations.Create(dir, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(paths[0]) != "0002_second.up.sql" {
+		t.Fatalf("second migration = %s, want version 0002", paths[0])
+	}
+
+	if _, err := migrations.Create(dir, "bad-name!"); err == nil {
+		t.Fatal("Create accepted an invalid name")
+	}
+}
@@ -0,0 +1,138 @@
+-- Schema previously created by AutoMigrate. IF NOT EXISTS lets databases
+-- created that way adopt the migration history.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name LONGTEXT,
+    first_name LONGTEXT,
+    last_name LONGTEXT,
+    email LONGTEXT,
+    password LONGTEXT,
+    role VARCHAR(16),
+    verify BOOLEAN,
+    provider VARCHAR(16) DEFAULT 'default',
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    CONSTRAINT chk_users_role CHECK (role IN ('admin','member')),
+    CONSTRAINT chk_users_provider CHECK (provider IN ('default','google','github')),
+    INDEX idx_users_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(191),
+    token_hash VARCHAR(64),
+    role VARCHAR(16),
+    invited_by BIGINT UNSIGNED,
+    expires_at DATETIME(3) NULL,
+    accepted_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    CONSTRAINT chk_invitations_role CHECK (role IN ('admin','member')),
+    INDEX idx_invitations_email (email),
+    UNIQUE INDEX idx_invitations_token_hash (token_hash),
+    INDEX idx_invitations_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id BIGINT UNSIGNED,
+    user_agent LONGTEXT,
+    ip VARCHAR(45),
+    created_at DATETIME(3) NULL,
+    last_seen_at DATETIME(3) NULL,
+    expires_at DATETIME(3) NULL,
+    revoked_at DATETIME(3) NULL,
+    INDEX idx_sessions_user_id (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    actor_id BIGINT UNSIGNED,
+    target_user_id BIGINT UNSIGNED,
+    action VARCHAR(64),
+    outcome VARCHAR(16),
+    ip VARCHAR(45),
+    user_agent LONGTEXT,
+    metadata TEXT,
+    created_at DATETIME(3) NULL,
+    INDEX idx_audit_logs_actor_id (actor_id),
+    INDEX idx_audit_logs_target_user_id (target_user_id),
+    INDEX idx_audit_logs_action (action),
+    INDEX idx_audit_logs_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    event_type VARCHAR(64),
+    aggregate_id VARCHAR(64),
+    payload TEXT,
+    occurred_at DATETIME(3) NULL,
+    published_at DATETIME(3) NULL,
+    attempts BIGINT,
+    next_attempt_at DATETIME(3) NULL,
+    last_error TEXT,
+    INDEX idx_outbox_messages_event_type (event_type),
+    INDEX idx_outbox_messages_aggregate_id (aggregate_id),
+    INDEX idx_outbox_messages_published_at (published_at),
+    INDEX idx_outbox_messages_next_attempt_at (next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    url LONGTEXT,
+    secret LONGTEXT,
+    events TEXT,
+    description LONGTEXT,
+    active BOOLEAN,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    INDEX idx_webhook_endpoints_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    endpoint_id BIGINT UNSIGNED,
+    event_id VARCHAR(36),
+    event_type VARCHAR(64),
+    payload TEXT,
+    status VARCHAR(16),
+    attempts BIGINT,
+    next_attempt_at DATETIME(3) NULL,
+    last_status_code BIGINT,
+    last_error TEXT,
+    delivered_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_webhook_delivery_event (endpoint_id, event_id),
+    INDEX idx_webhook_deliveries_status (status),
+    INDEX idx_webhook_deliveries_next_attempt_at (next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS service_clients (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name LONGTEXT,
+    client_id VARCHAR(64),
+    secret_hash LONGTEXT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_service_clients_client_id (client_id),
+    INDEX idx_service_clients_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    kid VARCHAR(64),
+    algorithm VARCHAR(16),
+    private_key TEXT,
+    active BOOLEAN,
+    active_slot BIGINT NULL,
+    rotated_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_signing_keys_k_id (kid),
+    UNIQUE INDEX idx_signing_keys_active_slot (active_slot)
+);
@@ -0,0 +1,4 @@
+-- The phone number and bio returned as the contacts of a user.
+
+ALTER TABLE users ADD COLUMN phone VARCHAR(32) NULL;
+ALTER TABLE users ADD COLUMN bio TEXT NULL;
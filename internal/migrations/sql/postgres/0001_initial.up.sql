@@ -0,0 +1,138 @@
+-- Schema previously created by AutoMigrate. IF NOT EXISTS lets databases
+-- created that way adopt the migration history.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT,
+    first_name TEXT,
+    last_name TEXT,
+    email TEXT,
+    password TEXT,
+    role VARCHAR(16),
+    verify BOOLEAN,
+    provider VARCHAR(16) DEFAULT 'default',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT chk_users_role CHECK (role IN ('admin','member')),
+    CONSTRAINT chk_users_provider CHECK (provider IN ('default','google','github'))
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT,
+    token_hash VARCHAR(64),
+    role VARCHAR(16),
+    invited_by BIGINT,
+    expires_at TIMESTAMPTZ,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT chk_invitations_role CHECK (role IN ('admin','member'))
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token_hash ON invitations (token_hash);
+CREATE INDEX IF NOT EXISTS idx_invitations_deleted_at ON invitations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id BIGINT,
+    user_agent TEXT,
+    ip VARCHAR(45),
+    created_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT,
+    target_user_id BIGINT,
+    action VARCHAR(64),
+    outcome VARCHAR(16),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    metadata TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_user_id ON audit_logs (target_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    event_type VARCHAR(64),
+    aggregate_id VARCHAR(64),
+    payload TEXT,
+    occurred_at TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    attempts BIGINT,
+    next_attempt_at TIMESTAMPTZ,
+    last_error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_event_type ON outbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate_id ON outbox_messages (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages (published_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT,
+    secret TEXT,
+    events TEXT,
+    description TEXT,
+    active BOOLEAN,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT,
+    event_id VARCHAR(36),
+    event_type VARCHAR(64),
+    payload TEXT,
+    status VARCHAR(16),
+    attempts BIGINT,
+    next_attempt_at TIMESTAMPTZ,
+    last_status_code BIGINT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_deliveries (endpoint_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS service_clients (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT,
+    client_id VARCHAR(64),
+    secret_hash TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_clients_client_id ON service_clients (client_id);
+CREATE INDEX IF NOT EXISTS idx_service_clients_deleted_at ON service_clients (deleted_at);
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id BIGSERIAL PRIMARY KEY,
+    kid VARCHAR(64),
+    algorithm VARCHAR(16),
+    private_key TEXT,
+    active BOOLEAN,
+    active_slot BIGINT,
+    rotated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_k_id ON signing_keys (kid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_active_slot ON signing_keys (active_slot);
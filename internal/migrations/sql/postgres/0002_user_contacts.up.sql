@@ -0,0 +1,4 @@
+-- The phone number and bio returned as the contacts of a user.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(32);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio TEXT;
@@ -0,0 +1,138 @@
+-- Schema previously created by AutoMigrate. IF NOT EXISTS lets databases
+-- created that way adopt the migration history.
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT,
+    first_name TEXT,
+    last_name TEXT,
+    email TEXT,
+    password TEXT,
+    role TEXT,
+    verify NUMERIC,
+    provider TEXT DEFAULT 'default',
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    CONSTRAINT chk_users_role CHECK (role IN ('admin','member')),
+    CONSTRAINT chk_users_provider CHECK (provider IN ('default','google','github'))
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT,
+    token_hash TEXT,
+    role TEXT,
+    invited_by INTEGER,
+    expires_at DATETIME,
+    accepted_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    CONSTRAINT chk_invitations_role CHECK (role IN ('admin','member'))
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token_hash ON invitations (token_hash);
+CREATE INDEX IF NOT EXISTS idx_invitations_deleted_at ON invitations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER,
+    user_agent TEXT,
+    ip TEXT,
+    created_at DATETIME,
+    last_seen_at DATETIME,
+    expires_at DATETIME,
+    revoked_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INTEGER,
+    target_user_id INTEGER,
+    action TEXT,
+    outcome TEXT,
+    ip TEXT,
+    user_agent TEXT,
+    metadata TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_user_id ON audit_logs (target_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id TEXT PRIMARY KEY,
+    event_type TEXT,
+    aggregate_id TEXT,
+    payload TEXT,
+    occurred_at DATETIME,
+    published_at DATETIME,
+    attempts INTEGER,
+    next_attempt_at DATETIME,
+    last_error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_event_type ON outbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate_id ON outbox_messages (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages (published_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT,
+    secret TEXT,
+    events TEXT,
+    description TEXT,
+    active NUMERIC,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER,
+    event_id TEXT,
+    event_type TEXT,
+    payload TEXT,
+    status TEXT,
+    attempts INTEGER,
+    next_attempt_at DATETIME,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_deliveries (endpoint_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS service_clients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT,
+    client_id TEXT,
+    secret_hash TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_clients_client_id ON service_clients (client_id);
+CREATE INDEX IF NOT EXISTS idx_service_clients_deleted_at ON service_clients (deleted_at);
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kid TEXT,
+    algorithm TEXT,
+    private_key TEXT,
+    active NUMERIC,
+    active_slot INTEGER,
+    rotated_at DATETIME,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_k_id ON signing_keys (kid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_active_slot ON signing_keys (active_slot);
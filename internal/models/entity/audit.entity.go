@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrAuditLogAppendOnly = errors.New("audit log entries cannot be modified")
+
+type AuditLogs struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ActorID      *uint     `json:"actor_id" gorm:"index"`
+	TargetUserID *uint     `json:"target_user_id" gorm:"index"`
+	Action       string    `json:"action" gorm:"size:64;index"`
+	Outcome      string    `json:"outcome" gorm:"size:16"`
+	IP           string    `json:"ip" gorm:"size:45"`
+	UserAgent    string    `json:"user_agent"`
+	Metadata     string    `json:"metadata" gorm:"type:text"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"index"`
+}
+
+// BeforeUpdate keeps the audit log append-only.
+func (a *AuditLogs) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+// BeforeDelete keeps the audit log append-only.
+func (a *AuditLogs) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Invitations struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Email      string         `json:"email" gorm:"index"`
+	TokenHash  string         `json:"-" gorm:"size:64;uniqueIndex"`
+	Role       string         `json:"role" gorm:"size:16;check:role IN ('admin','member')"`
+	InvitedBy  uint           `json:"invited_by"`
+	ExpiresAt  time.Time      `json:"expiresAt"`
+	AcceptedAt *time.Time     `json:"acceptedAt"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `json:"deletedAt" gorm:"index"`
+}
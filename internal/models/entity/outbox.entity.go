@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type OutboxMessages struct {
+	ID            string     `json:"id" gorm:"primaryKey;size:36"`
+	EventType     string     `json:"event_type" gorm:"size:64;index"`
+	AggregateID   string     `json:"aggregate_id" gorm:"size:64;index"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	OccurredAt    time.Time  `json:"occurredAt"`
+	PublishedAt   *time.Time `json:"publishedAt" gorm:"index"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index"`
+	LastError     string     `json:"last_error" gorm:"type:text"`
+}
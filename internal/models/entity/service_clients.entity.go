@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ServiceClients struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name"`
+	ClientID   string         `json:"client_id" gorm:"size:64;uniqueIndex"`
+	SecretHash string         `json:"-"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `json:"deletedAt" gorm:"index"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Sessions struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:36"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip" gorm:"size:45"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// Active reports whether the session can still authenticate requests.
+func (s *Sessions) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
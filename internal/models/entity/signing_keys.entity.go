@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type SigningKeys struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	KID        string `json:"kid" gorm:"column:kid;size:64;uniqueIndex"`
+	Algorithm  string `json:"algorithm" gorm:"size:16"`
+	PrivateKey string `json:"-" gorm:"type:text"`
+	Active     bool   `json:"active"`
+	// ActiveSlot is 1 for the active key and nil otherwise. Its unique
+	// index allows a single active key.
+	ActiveSlot *int       `json:"-" gorm:"uniqueIndex"`
+	RotatedAt  *time.Time `json:"rotatedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
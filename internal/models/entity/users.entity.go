@@ -13,9 +13,11 @@ type Users struct {
 	LastName  string         `json:"last_name"`
 	Email     string         `json:"email"`
 	Password  string         `json:"password"`
-	Role      string         `json:"role" gorm:"type:enum('admin','member')"`
+	Role      string         `json:"role" gorm:"size:16;check:role IN ('admin','member')"`
 	Verify    bool           `json:"verify"`
-	Provider  *string        `json:"provider" gorm:"type:enum('default', 'google', 'github');default:'default'"`
+	Provider  *string        `json:"provider" gorm:"size:16;default:'default';check:provider IN ('default','google','github')"`
+	Phone     *string        `json:"phone" gorm:"size:32"`
+	Bio       *string        `json:"bio" gorm:"type:text"`
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
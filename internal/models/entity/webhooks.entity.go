@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookEndpoints struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	URL         string         `json:"url"`
+	Secret      string         `json:"-"`
+	Events      string         `json:"events" gorm:"type:text"`
+	Description string         `json:"description"`
+	Active      bool           `json:"active"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `json:"deletedAt" gorm:"index"`
+}
+
+// Statuses of a webhook delivery.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+	WebhookDeliverySkipped   = "skipped"
+)
+
+type WebhookDeliveries struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EndpointID     uint       `json:"endpoint_id" gorm:"uniqueIndex:idx_webhook_delivery_event"`
+	EventID        string     `json:"event_id" gorm:"size:36;uniqueIndex:idx_webhook_delivery_event"`
+	EventType      string     `json:"event_type" gorm:"size:64"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"size:16;index"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt" gorm:"index"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
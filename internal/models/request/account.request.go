@@ -0,0 +1,18 @@
+package request
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type IdentityResponse struct {
+	Provider string `json:"provider"`
+	Email    string `json:"email"`
+}
+
+type UserDataExport struct {
+	ExportedAt string             `json:"exportedAt"`
+	Profile    UserProfile        `json:"profile"`
+	Identities []IdentityResponse `json:"identities"`
+	Sessions   []SessionResponse  `json:"sessions"`
+	AuditLogs  []AuditLogResponse `json:"auditLogs"`
+}
@@ -0,0 +1,30 @@
+package request
+
+import "encoding/json"
+
+type AuditLogResponse struct {
+	ID           uint            `json:"id"`
+	ActorID      *uint           `json:"actor_id"`
+	TargetUserID *uint           `json:"target_user_id"`
+	Action       string          `json:"action"`
+	Outcome      string          `json:"outcome"`
+	IP           string          `json:"ip"`
+	UserAgent    string          `json:"user_agent"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt    string          `json:"createdAt"`
+}
+
+type AuditLogPageResponse struct {
+	Data []AuditLogResponse `json:"data"`
+	Meta PageMeta           `json:"meta"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type ChangeRoleResponse struct {
+	UserID       uint   `json:"user_id"`
+	Role         string `json:"role"`
+	PreviousRole string `json:"previous_role"`
+}
@@ -6,10 +6,10 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=6"`
+	FirstName string `json:"first_name" validate:"required,personname"`
+	LastName  string `json:"last_name" validate:"required,personname"`
+	Email     string `json:"email" validate:"required,email,notdisposable"`
+	Password  string `json:"password" validate:"required,password"`
 }
 
 type VerifyRequest struct {
@@ -19,3 +19,20 @@ type VerifyRequest struct {
 type ResendVerifyRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
+
+// TokenResponse is what every sign in returns in API v1: password and OAuth
+// logins and accepted invitations. In cookie mode Token is left out, as it
+// is set in the auth cookie, and CSRFToken is returned instead.
+type TokenResponse struct {
+	Token     string `json:"token,omitempty"`
+	TokenType string `json:"token_type"`
+	// ExpiresIn is the lifetime of the token in seconds.
+	ExpiresIn int64        `json:"expires_in"`
+	CSRFToken string       `json:"csrf_token,omitempty"`
+	User      UserResponse `json:"user"`
+}
+
+// RegisterResponse is returned by a password registration in API v1.
+type RegisterResponse struct {
+	User UserResponse `json:"user"`
+}
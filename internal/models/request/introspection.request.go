@@ -0,0 +1,31 @@
+package request
+
+type CreateServiceClientRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type ServiceClientResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+type ServiceClientListResponse struct {
+	Data []ServiceClientResponse `json:"data"`
+}
+
+// IntrospectionResponse follows RFC 7662. Revoked is an extension telling
+// callers that an otherwise valid token belongs to a revoked session.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Revoked   bool   `json:"revoked,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+}
@@ -0,0 +1,29 @@
+package request
+
+type CreateInvitationRequest struct {
+	Email          string `json:"email" validate:"required,email"`
+	Role           string `json:"role" validate:"omitempty,oneof=admin member"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
+
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" validate:"required"`
+	FirstName string `json:"first_name" validate:"required,personname"`
+	LastName  string `json:"last_name" validate:"required,personname"`
+	Password  string `json:"password" validate:"required,password"`
+}
+
+type InvitationResponse struct {
+	ID         uint    `json:"id"`
+	Email      string  `json:"email"`
+	Role       string  `json:"role"`
+	InvitedBy  uint    `json:"invited_by"`
+	Token      string  `json:"token,omitempty"`
+	ExpiresAt  string  `json:"expiresAt"`
+	AcceptedAt *string `json:"acceptedAt"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+type InvitationListResponse struct {
+	Data []InvitationResponse `json:"data"`
+}
@@ -0,0 +1,8 @@
+package request
+
+// PageMeta describes the page of a paginated list response.
+type PageMeta struct {
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+}
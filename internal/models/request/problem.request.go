@@ -0,0 +1,16 @@
+package request
+
+import "micro/internal/validation"
+
+// Problem is the RFC 7807 body of every error response. Code is stable and
+// meant for clients to branch on; Title and Detail are for humans.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail"`
+	Code      string                  `json:"code"`
+	Instance  string                  `json:"instance,omitempty"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []validation.FieldError `json:"errors,omitempty"`
+}
@@ -30,7 +30,27 @@ type UserResponse struct {
 	FirstName string   `json:"first_name"`
 	LastName  string   `json:"last_name"`
 	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	Verify    bool     `json:"verify"`
 	CreatedAt string   `json:"createdAt"`
 	UpdatedAt string   `json:"updatedAt"`
 	Contacts  Contacts `json:"contacts"`
 }
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+// UserRecord is one account in the export-users / import-users format. The
+// password is the bcrypt hash, empty for accounts created through OAuth.
+type UserRecord struct {
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email" validate:"required,email"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	Role         string `json:"role" validate:"oneof=admin member"`
+	Verify       bool   `json:"verify"`
+	Provider     string `json:"provider,omitempty" validate:"omitempty,oneof=default google github"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+}
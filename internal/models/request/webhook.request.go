@@ -0,0 +1,43 @@
+package request
+
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16"`
+	Events      []string `json:"events" validate:"required,min=1,dive,required"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+type WebhookEndpointResponse struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+type WebhookEndpointListResponse struct {
+	Data []WebhookEndpointResponse `json:"data"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint    `json:"id"`
+	EndpointID     uint    `json:"endpoint_id"`
+	EventID        string  `json:"event_id"`
+	EventType      string  `json:"event_type"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	LastStatusCode int     `json:"last_status_code"`
+	LastError      string  `json:"last_error,omitempty"`
+	NextAttemptAt  string  `json:"nextAttemptAt"`
+	DeliveredAt    *string `json:"deliveredAt"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+type WebhookDeliveryPageResponse struct {
+	Data []WebhookDeliveryResponse `json:"data"`
+	Meta PageMeta                  `json:"meta"`
+}
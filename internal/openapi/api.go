@@ -0,0 +1,344 @@
+package openapi
+
+import (
+	"micro/internal/models/request"
+	"net/http"
+	"strconv"
+)
+
+// SpecPath and DocsPath are where the document and its UI are served, and
+// AssetPath the Swagger UI files the UI loads.
+const (
+	SpecPath  = "/api/openapi.json"
+	DocsPath  = "/api/docs"
+	AssetPath = DocsPath + "/:file"
+)
+
+var (
+	userAuth   = []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+	clientAuth = []map[string][]string{{"clientBasic": {}}}
+)
+
+// api builds the operations of the document.
+type api struct {
+	*Document
+}
+
+// reply is one documented response of an operation.
+type reply struct {
+	status   int
+	response *Response
+}
+
+func (a api) ok(status int, description string, body interface{}) reply {
+	response := &Response{Description: description}
+	if body != nil {
+		response.Content = a.JSON(body)
+	}
+	return reply{status, response}
+}
+
+func (a api) problem(status int, description string) reply {
+	return reply{status, &Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/problem+json": {Schema: a.Schema(request.Problem{})}},
+	}}
+}
+
+func (a api) invalid() reply {
+	return a.problem(http.StatusBadRequest, "Invalid request")
+}
+
+func (a api) notFound(resource string) reply {
+	return a.problem(http.StatusNotFound, resource+" not found")
+}
+
+// op returns an operation. Operations with security also document the 401
+// response, and admin ones the 403.
+func (a api) op(tag, operationID, summary string, security []map[string][]string, replies ...reply) *Operation {
+	if security != nil {
+		replies = append(replies, a.problem(http.StatusUnauthorized, "Missing, invalid or revoked token"))
+	}
+	op := &Operation{Tags: []string{tag}, OperationID: operationID, Summary: summary, Security: security, Responses: map[string]*Response{}}
+	if tag == "admin" {
+		op.Description = "Requires the admin role."
+		replies = append(replies, a.problem(http.StatusForbidden, "The user is not an admin"))
+	}
+	for _, r := range replies {
+		op.Responses[strconv.Itoa(r.status)] = r.response
+	}
+	return op
+}
+
+func (a api) withBody(op *Operation, body interface{}) *Operation {
+	op.RequestBody = &RequestBody{Required: true, Content: a.JSON(body)}
+	return op
+}
+
+func withParams(op *Operation, params ...Parameter) *Operation {
+	op.Parameters = append(op.Parameters, params...)
+	return op
+}
+
+func idParam(description string) Parameter {
+	return Parameter{Name: "id", In: "path", Description: description, Required: true, Schema: &Schema{Type: "integer"}}
+}
+
+func queryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var paginationParams = []Parameter{
+	queryParam("page", "Page number, from 1", &Schema{Type: "integer"}),
+	queryParam("per_page", "Entries per page", &Schema{Type: "integer"}),
+}
+
+var auditFilterParams = []Parameter{
+	queryParam("action", "Only entries with this action", &Schema{Type: "string"}),
+	queryParam("outcome", "Only entries with this outcome", &Schema{Type: "string", Enum: []string{"success", "failure"}}),
+	queryParam("from", "Only entries at or after this time", &Schema{Type: "string", Format: "date-time"}),
+	queryParam("to", "Only entries before this time", &Schema{Type: "string", Format: "date-time"}),
+}
+
+// API describes every route mounted by routes.AllRoutes. The unversioned
+// /api paths are deprecated aliases of /api/v1 and are not listed.
+func API() *Document {
+	d := New(Info{
+		Title:   "Auth API",
+		Version: "1",
+		Description: "Accounts, sessions and tokens. Errors are RFC 7807 problem documents whose code " +
+			"member is stable. The unversioned /api paths are deprecated aliases of /api/v1.",
+	})
+	d.Tags = []Tag{
+		{Name: "auth", Description: "Sign up, sign in and sign out"},
+		{Name: "users", Description: "The signed in user's account"},
+		{Name: "admin", Description: "Administration, restricted to admins"},
+		{Name: "oauth", Description: "Token introspection for downstream services"},
+		{Name: "system", Description: "Probes, metrics, keys and this document"},
+	}
+	d.Components.SecuritySchemes["bearerAuth"] = &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	d.Components.SecuritySchemes["cookieAuth"] = &SecurityScheme{
+		Type: "apiKey", In: "cookie", Name: "access_token",
+		Description: "In cookie mode, named by AUTH_COOKIE_NAME. Unsafe methods must also echo the CSRF cookie in the X-CSRF-Token header.",
+	}
+	d.Components.SecuritySchemes["clientBasic"] = &SecurityScheme{
+		Type: "http", Scheme: "basic",
+		Description: "Service client credentials, also accepted as client_id and client_secret form fields.",
+	}
+
+	a := api{d}
+	addAuth(a)
+	addUsers(a)
+	addAdmin(a)
+	addOAuth(a)
+	addSystem(a)
+	return d
+}
+
+func addAuth(a api) {
+	a.Add("POST", "/api/v1/auth/login", a.withBody(a.op("auth", "login", "Sign in with email and password", nil,
+		a.ok(http.StatusOK, "Signed in", request.TokenResponse{}),
+		a.invalid(),
+		a.problem(http.StatusUnauthorized, "Invalid email or password"),
+		a.problem(http.StatusForbidden, "Account not verified"),
+	), request.LoginRequest{}))
+
+	a.Add("POST", "/api/v1/auth/register", a.withBody(a.op("auth", "register", "Create an account with a password", nil,
+		a.ok(http.StatusCreated, "Account created", request.RegisterResponse{}),
+		a.invalid(),
+		a.problem(http.StatusForbidden, "Registration closed, invite only or not open to the email domain"),
+		a.problem(http.StatusConflict, "Email already in use"),
+	), request.RegisterRequest{}))
+
+	a.Add("POST", "/api/v1/auth/logout", a.op("auth", "logout", "Revoke the current session", userAuth,
+		a.ok(http.StatusNoContent, "Signed out", nil),
+	))
+
+	for _, provider := range []string{"google", "github"} {
+		a.Add("GET", "/api/v1/auth/"+provider, withParams(a.op("auth", provider+"Redirect", "Start signing in with "+provider, nil,
+			a.ok(http.StatusFound, "Redirect to the provider", nil),
+			a.notFound("Provider"),
+		), queryParam("from", "Frontend path to return to in cookie mode", &Schema{Type: "string"})))
+
+		a.Add("GET", "/api/v1/auth/"+provider+"/callback", withParams(a.op("auth", provider+"Callback", "Finish signing in with "+provider, nil,
+			a.ok(http.StatusOK, "Signed in to an existing account", request.TokenResponse{}),
+			a.ok(http.StatusCreated, "Signed in to a new account", request.TokenResponse{}),
+			a.ok(http.StatusFound, "In cookie mode, redirect to the frontend with the auth cookie set", nil),
+			a.invalid(),
+			a.problem(http.StatusUnauthorized, "Missing or rejected authorization code"),
+			a.problem(http.StatusForbidden, "Registration denied"),
+			a.problem(http.StatusConflict, "Account registered with another provider"),
+		),
+			queryParam("code", "Authorization code", &Schema{Type: "string"}),
+			queryParam("state", "Frontend path to return to in cookie mode", &Schema{Type: "string"}),
+		))
+	}
+
+	a.Add("POST", "/api/v1/invitations/accept", a.withBody(a.op("auth", "acceptInvitation", "Create an account from an invitation", nil,
+		a.ok(http.StatusCreated, "Account created and signed in", request.TokenResponse{}),
+		a.problem(http.StatusBadRequest, "Invalid request, or invalid or expired invitation"),
+		a.problem(http.StatusConflict, "Email already in use"),
+	), request.AcceptInvitationRequest{}))
+}
+
+func addUsers(a api) {
+	a.Add("DELETE", "/api/v1/users/me", a.withBody(a.op("users", "deleteAccount", "Schedule the account for deletion", userAuth,
+		a.ok(http.StatusNoContent, "Account scheduled for deletion", nil),
+		a.problem(http.StatusForbidden, "A recent login is required"),
+	), request.DeleteAccountRequest{}))
+
+	a.Add("GET", "/api/v1/users/me/export", a.op("users", "exportUserData", "Download the account's data", userAuth,
+		a.ok(http.StatusOK, "The account's data", request.UserDataExport{}),
+	))
+
+	a.Add("GET", "/api/v1/users/me/sessions", a.op("users", "listSessions", "List the active sessions", userAuth,
+		a.ok(http.StatusOK, "Active sessions", request.SessionListResponse{}),
+	))
+
+	a.Add("DELETE", "/api/v1/users/me/sessions/:id", a.op("users", "revokeSession", "Revoke a session", userAuth,
+		a.ok(http.StatusNoContent, "Session revoked", nil),
+		a.notFound("Session"),
+	))
+
+	a.Add("GET", "/api/v1/users/me/security-activity", withParams(a.op("users", "securityActivity", "List the account's audit log", userAuth,
+		a.ok(http.StatusOK, "Audit log entries", request.AuditLogPageResponse{}),
+		a.invalid(),
+	), append(auditFilterParams, paginationParams...)...))
+}
+
+func addAdmin(a api) {
+	a.Add("PUT", "/api/v1/users/:id/role", a.withBody(withParams(a.op("admin", "changeUserRole", "Change a user's role", userAuth,
+		a.ok(http.StatusOK, "Role changed", request.ChangeRoleResponse{}),
+		a.invalid(),
+		a.notFound("User"),
+	), idParam("User id")), request.ChangeRoleRequest{}))
+
+	a.Add("GET", "/api/v1/audit-logs", withParams(a.op("admin", "listAuditLogs", "List the audit log", userAuth,
+		a.ok(http.StatusOK, "Audit log entries", request.AuditLogPageResponse{}),
+		a.invalid(),
+	), append(append([]Parameter{
+		queryParam("actor_id", "Only entries of actions by this user", &Schema{Type: "integer"}),
+		queryParam("user_id", "Only entries of actions on this user", &Schema{Type: "integer"}),
+	}, auditFilterParams...), paginationParams...)...))
+
+	a.Add("GET", "/api/v1/invitations", a.op("admin", "listInvitations", "List invitations", userAuth,
+		a.ok(http.StatusOK, "Invitations", request.InvitationListResponse{}),
+	))
+	a.Add("POST", "/api/v1/invitations", a.withBody(a.op("admin", "createInvitation", "Invite someone to register", userAuth,
+		a.ok(http.StatusCreated, "Invitation created, with its token", request.InvitationResponse{}),
+		a.invalid(),
+		a.problem(http.StatusForbidden, "Registration is closed"),
+		a.problem(http.StatusConflict, "Email already in use"),
+	), request.CreateInvitationRequest{}))
+	a.Add("DELETE", "/api/v1/invitations/:id", withParams(a.op("admin", "revokeInvitation", "Revoke an invitation", userAuth,
+		a.ok(http.StatusNoContent, "Invitation revoked", nil),
+		a.notFound("Invitation"),
+	), idParam("Invitation id")))
+
+	a.Add("GET", "/api/v1/webhooks", a.op("admin", "listWebhooks", "List webhook endpoints", userAuth,
+		a.ok(http.StatusOK, "Webhook endpoints", request.WebhookEndpointListResponse{}),
+	))
+	a.Add("POST", "/api/v1/webhooks", a.withBody(a.op("admin", "createWebhook", "Register a webhook endpoint", userAuth,
+		a.ok(http.StatusCreated, "Endpoint created, with its secret", request.WebhookEndpointResponse{}),
+		a.invalid(),
+	), request.WebhookEndpointRequest{}))
+	a.Add("PUT", "/api/v1/webhooks/:id", a.withBody(withParams(a.op("admin", "updateWebhook", "Update a webhook endpoint", userAuth,
+		a.ok(http.StatusOK, "Endpoint updated", request.WebhookEndpointResponse{}),
+		a.invalid(),
+		a.notFound("Webhook"),
+	), idParam("Webhook id")), request.WebhookEndpointRequest{}))
+	a.Add("DELETE", "/api/v1/webhooks/:id", withParams(a.op("admin", "deleteWebhook", "Delete a webhook endpoint", userAuth,
+		a.ok(http.StatusNoContent, "Endpoint deleted", nil),
+		a.notFound("Webhook"),
+	), idParam("Webhook id")))
+	a.Add("GET", "/api/v1/webhooks/:id/deliveries", withParams(a.op("admin", "listWebhookDeliveries", "List the deliveries of an endpoint", userAuth,
+		a.ok(http.StatusOK, "Deliveries", request.WebhookDeliveryPageResponse{}),
+		a.notFound("Webhook"),
+	), append([]Parameter{idParam("Webhook id")}, paginationParams...)...))
+	a.Add("POST", "/api/v1/webhooks/deliveries/:id/redeliver", withParams(a.op("admin", "redeliverWebhook", "Schedule a delivery again", userAuth,
+		a.ok(http.StatusAccepted, "Redelivery scheduled", request.WebhookDeliveryResponse{}),
+		a.notFound("Delivery"),
+	), idParam("Delivery id")))
+
+	a.Add("GET", "/api/v1/service-clients", a.op("admin", "listServiceClients", "List service clients", userAuth,
+		a.ok(http.StatusOK, "Service clients", request.ServiceClientListResponse{}),
+	))
+	a.Add("POST", "/api/v1/service-clients", a.withBody(a.op("admin", "createServiceClient", "Register a service client", userAuth,
+		a.ok(http.StatusCreated, "Client created, with its secret", request.ServiceClientResponse{}),
+		a.invalid(),
+	), request.CreateServiceClientRequest{}))
+	a.Add("DELETE", "/api/v1/service-clients/:id", withParams(a.op("admin", "deleteServiceClient", "Delete a service client", userAuth,
+		a.ok(http.StatusNoContent, "Client deleted", nil),
+		a.notFound("Service client"),
+	), idParam("Service client id")))
+}
+
+func addOAuth(a api) {
+	op := a.op("oauth", "introspectToken", "Introspect a token (RFC 7662)", clientAuth,
+		a.ok(http.StatusOK, "State of the token", request.IntrospectionResponse{}),
+	)
+	// Introspection answers errors the RFC 6749 way rather than with problems.
+	op.Description = "Errors follow RFC 6749: {\"error\": \"invalid_client\"} and the like."
+	op.Responses["400"] = &Response{Description: "The token is missing"}
+	op.Responses["401"] = &Response{Description: "Invalid client credentials"}
+	op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+		"application/x-www-form-urlencoded": {Schema: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"token":         {Type: "string"},
+				"client_id":     {Type: "string"},
+				"client_secret": {Type: "string"},
+			},
+			Required: []string{"token"},
+		}},
+	}}
+	a.Add("POST", "/api/v1/oauth/introspect", op)
+}
+
+func addSystem(a api) {
+	status := &Schema{Type: "object", Properties: map[string]*Schema{"status": {Type: "string"}}}
+	text := func(mediaType, description string) reply {
+		return reply{http.StatusOK, &Response{
+			Description: description,
+			Content:     map[string]MediaType{mediaType: {Schema: &Schema{Type: "string"}}},
+		}}
+	}
+
+	a.Add("GET", "/healthz", a.op("system", "healthz", "Liveness probe", nil,
+		a.ok(http.StatusOK, "The process serves requests", status),
+	))
+	a.Add("GET", "/readyz", a.op("system", "readyz", "Readiness probe", nil,
+		a.ok(http.StatusOK, "Ready to take traffic", status),
+		a.ok(http.StatusServiceUnavailable, "A dependency is unusable or shutdown has started", &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"status": {Type: "string"},
+				"checks": {Type: "object", AdditionalProperties: &Schema{Type: "string", Enum: []string{"down"}}, Description: "The failing checks by name"},
+			},
+		}),
+	))
+	a.Add("GET", "/metrics", a.op("system", "metrics", "Prometheus metrics", nil,
+		text("text/plain", "Metrics in the Prometheus text format"),
+	))
+	a.Add("GET", "/.well-known/jwks.json", a.op("system", "jwks", "Public keys tokens are signed with (RFC 7517)", nil,
+		a.ok(http.StatusOK, "JSON Web Key Set", &Schema{Type: "object", Properties: map[string]*Schema{
+			"keys": {Type: "array", Items: &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}},
+		}}),
+	))
+	a.Add("GET", SpecPath, a.op("system", "openapi", "This document", nil,
+		a.ok(http.StatusOK, "OpenAPI 3 document", &Schema{Type: "object"}),
+	))
+	a.Add("GET", DocsPath, a.op("system", "docs", "Interactive documentation", nil,
+		text("text/html", "HTML page rendering this document"),
+	))
+	a.Add("GET", AssetPath, withParams(a.op("system", "docsAsset", "Swagger UI file of the documentation page", nil,
+		reply{http.StatusOK, &Response{
+			Description: "The stylesheet or script",
+			Content: map[string]MediaType{
+				"text/css":               {Schema: &Schema{Type: "string"}},
+				"application/javascript": {Schema: &Schema{Type: "string"}},
+			},
+		}},
+		a.notFound("File"),
+	), Parameter{Name: "file", In: "path", Required: true, Schema: &Schema{Type: "string", Enum: []string{"swagger-ui.css", "swagger-ui-bundle.js"}}}))
+}
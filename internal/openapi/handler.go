@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"path"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler serves the document as JSON. It is encoded once.
+func (d *Document) Handler() fiber.Handler {
+	body, err := json.Marshal(d)
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(body)
+	}
+}
+
+// The UI is Swagger UI, whose files are embedded so that the page loads
+// nothing from third parties. ui/README.md records their version.
+//
+//go:embed ui/swagger-ui.css ui/swagger-ui-bundle.js
+var assets embed.FS
+
+var assetTypes = map[string]string{
+	".css": "text/css; charset=utf-8",
+	".js":  "application/javascript; charset=utf-8",
+}
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Auth API</title>
+<link rel="stylesheet" href="{{.Docs}}/swagger-ui.css">
+</head>
+<body>
+<div id="docs"></div>
+<script src="{{.Docs}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: {{.Spec}}, dom_id: "#docs"});
+</script>
+</body>
+</html>
+`))
+
+// UIHandler serves an interactive documentation page for the document at
+// specURL. The page loads its files from AssetHandler under DocsPath.
+func UIHandler(specURL string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return docsPage.Execute(c.Response().BodyWriter(), struct{ Docs, Spec string }{DocsPath, specURL})
+	}
+}
+
+// AssetHandler serves the embedded Swagger UI files at AssetPath.
+func AssetHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("file")
+		body, err := assets.ReadFile("ui/" + name)
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+		c.Set(fiber.HeaderContentType, assetTypes[path.Ext(name)])
+		c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+		return c.Send(body)
+	}
+}
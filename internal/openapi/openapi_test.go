@@ -0,0 +1,118 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"io"
+	"micro/config"
+	"micro/internal/handlers"
+	"micro/internal/logging"
+	"micro/internal/metrics"
+	"micro/internal/middleware"
+	"micro/internal/models/request"
+	"micro/internal/openapi"
+	"micro/internal/routes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newApp(doc *openapi.Document) *fiber.App {
+	app := fiber.New()
+	routes.AllRoutes(app, &handlers.Handler{}, middleware.New(nil, config.CookieConfig{}, logging.Discard()), metrics.New(), doc)
+	return app
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	doc := openapi.API()
+	for _, route := range newApp(doc).GetRoutes(true) {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := route.Path
+		// The unversioned paths are aliases of /api/v1.
+		if strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/api/v1/") && path != openapi.SpecPath && path != openapi.DocsPath && path != openapi.AssetPath {
+			path = "/api/v1" + strings.TrimPrefix(path, "/api")
+		}
+		if !doc.Has(route.Method, path) {
+			t.Errorf("%s %s is not documented in openapi.API", route.Method, path)
+		}
+	}
+}
+
+func TestSchemaFollowsTags(t *testing.T) {
+	doc := openapi.New(openapi.Info{})
+	doc.Schema(request.RegisterRequest{})
+
+	schema := doc.Components.Schemas["RegisterRequest"]
+	if schema == nil {
+		t.Fatal("RegisterRequest is not in the components")
+	}
+	if got := strings.Join(schema.Required, ","); got != "first_name,last_name,email,password" {
+		t.Errorf("required = %s", got)
+	}
+	if email := schema.Properties["email"]; email.Format != "email" {
+		t.Errorf("email format = %q, want email", email.Format)
+	}
+	if password := schema.Properties["password"]; password.MinLength == nil || *password.MinLength != 8 {
+		t.Errorf("password minLength = %v, want 8", password.MinLength)
+	}
+}
+
+func TestServesDocument(t *testing.T) {
+	resp, err := newApp(openapi.API()).Test(httptest.NewRequest(http.MethodGet, openapi.SpecPath, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("document is not JSON: %v", err)
+	}
+	if decoded["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v", decoded["openapi"])
+	}
+	if resp.Header.Get("Deprecation") != "" {
+		t.Error("the document is served with the deprecation headers of /api")
+	}
+}
+
+func TestServesUIWithoutThirdPartyAssets(t *testing.T) {
+	app := newApp(openapi.API())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, openapi.DocsPath, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(page), "https://") {
+		t.Errorf("docs page loads remote assets:\n%s", page)
+	}
+
+	for file, contentType := range map[string]string{
+		"swagger-ui.css":       "text/css",
+		"swagger-ui-bundle.js": "application/javascript",
+	} {
+		if !strings.Contains(string(page), openapi.DocsPath+"/"+file) {
+			t.Errorf("docs page does not load %s", file)
+		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, openapi.DocsPath+"/"+file, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), contentType) {
+			t.Errorf("%s = %d %s", file, resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+		}
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, openapi.DocsPath+"/index.html", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown asset = %d, want 404", resp.StatusCode)
+	}
+}
@@ -1,29 +1,77 @@
 package provider
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"context"
+	"fmt"
+	"micro/config"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/github"
 )
 
-var GithubOauthConfig *oauth2.Config
+const (
+	githubUserURL   = "https://api.github.com/user"
+	githubEmailsURL = "https://api.github.com/user/emails"
+)
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file")
-	}
+type Github struct {
+	config *oauth2.Config
+}
 
-	// config Github provider
-	GithubOauthConfig = &oauth2.Config{
-		ClientID:     os.Getenv("GITHUB_CLIENT_ID"),
-		ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET_KEY"),
-		RedirectURL:  "http://localhost:3000/api/auth/github/callback",
+func NewGithub(cfg config.OAuthProviderConfig) *Github {
+	return &Github{config: &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
 		Scopes:       []string{"user:email"},
 		Endpoint:     github.Endpoint,
+	}}
+}
+
+func (g *Github) Name() string {
+	return "github"
+}
+
+func (g *Github) AuthCodeURL(state string) string {
+	return g.config.AuthCodeURL(state)
+}
+
+func (g *Github) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	return g.config.Exchange(withClient(ctx), code)
+}
+
+// UserInfo splits the Github display name into first and last name and
+// reads the primary email, which the profile does not always expose.
+func (g *Github) UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	var user struct {
+		Name string `json:"name"`
+	}
+	if err := getJSON(ctx, g.config, token, githubUserURL, &user); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	var emails []struct {
+		Email   string `json:"email"`
+		Primary bool   `json:"primary"`
+	}
+	if err := getJSON(ctx, g.config, token, githubEmailsURL, &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+
+	info := &UserInfo{}
+	if nameParts := strings.Fields(user.Name); len(nameParts) > 0 {
+		info.FirstName = nameParts[0]
+		info.LastName = strings.Join(nameParts[1:], " ")
+	}
+	for _, e := range emails {
+		if e.Primary {
+			info.Email = e.Email
+			break
+		}
+	}
+	if info.Email == "" {
+		return nil, fmt.Errorf("no primary email found")
 	}
+	return info, nil
 }
@@ -1,28 +1,55 @@
 package provider
 
 import (
-	"log"
-	"os"
+	"context"
+	"fmt"
+	"micro/config"
 
-	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
-var GoogleOauthConfig *oauth2.Config
+const googleUserInfoURL = "https://www.googleapis.com/oauth2/v3/userinfo"
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file")
-	}
+type Google struct {
+	config *oauth2.Config
+}
 
-	// config Google provider
-	GoogleOauthConfig = &oauth2.Config{
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET_KEY"),
-		RedirectURL:  "http://localhost:3000/api/auth/google/callback",
+func NewGoogle(cfg config.OAuthProviderConfig) *Google {
+	return &Google{config: &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
 		Scopes:       []string{"profile", "email"},
 		Endpoint:     google.Endpoint,
+	}}
+}
+
+func (g *Google) Name() string {
+	return "google"
+}
+
+func (g *Google) AuthCodeURL(state string) string {
+	return g.config.AuthCodeURL(state)
+}
+
+func (g *Google) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	return g.config.Exchange(withClient(ctx), code)
+}
+
+func (g *Google) UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	var userInfo struct {
+		Email      string `json:"email"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+	}
+	if err := getJSON(ctx, g.config, token, googleUserInfoURL, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
+
+	return &UserInfo{
+		Email:     userInfo.Email,
+		FirstName: userInfo.GivenName,
+		LastName:  userInfo.FamilyName,
+	}, nil
 }
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
+)
+
+// httpClient makes every call to the providers. Its transport records a
+// client span per request.
+var httpClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// withClient makes the oauth2 package send its requests through httpClient.
+func withClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+}
+
+// UserInfo is the profile an OAuth provider returns for the signed in user.
+type UserInfo struct {
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// Provider is an OAuth login provider.
+type Provider interface {
+	// Name is the value stored in the users.provider column.
+	Name() string
+	AuthCodeURL(state string) string
+	Exchange(ctx context.Context, code string) (*oauth2.Token, error)
+	UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error)
+}
+
+// getJSON fetches url with the provider client and decodes the JSON answer
+// into target.
+func getJSON(ctx context.Context, config *oauth2.Config, token *oauth2.Token, url string, target interface{}) error {
+	client := config.Client(withClient(ctx), token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows down AuditLogRepository.List. Zero values are
+// ignored.
+type AuditLogFilter struct {
+	ActorID      *uint
+	TargetUserID *uint
+	// InvolvedUserID matches entries where the user is either actor or target.
+	InvolvedUserID *uint
+	Action         string
+	Outcome        string
+	From           time.Time
+	To             time.Time
+}
+
+// AuditLogRepository stores the append-only audit log: entries are created
+// and never updated.
+type AuditLogRepository interface {
+	Create(entry *entity.AuditLogs) error
+	// List returns up to limit entries matching filter, newest first,
+	// skipping the first offset, along with the number of matching entries.
+	List(filter AuditLogFilter, offset, limit int) ([]entity.AuditLogs, int64, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func (r *auditLogRepository) Create(entry *entity.AuditLogs) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) List(filter AuditLogFilter, offset, limit int) ([]entity.AuditLogs, int64, error) {
+	query := r.db.Model(&entity.AuditLogs{})
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.TargetUserID != nil {
+		query = query.Where("target_user_id = ?", *filter.TargetUserID)
+	}
+	if filter.InvolvedUserID != nil {
+		query = query.Where("actor_id = ? OR target_user_id = ?", *filter.InvolvedUserID, *filter.InvolvedUserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []entity.AuditLogs
+	err := query.Order("created_at desc, id desc").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	Create(invitation *entity.Invitations) error
+	// List returns every invitation, newest first.
+	List() ([]entity.Invitations, error)
+	FindByTokenHash(tokenHash string) (*entity.Invitations, error)
+	// FindPending returns the newest unused invitation for email that has
+	// not expired at now.
+	FindPending(email string, now time.Time) (*entity.Invitations, error)
+	// MarkAccepted marks an unused invitation as accepted, or returns
+	// ErrNotFound when it was accepted in the meantime, so that concurrent
+	// accepts of one token create a single account.
+	MarkAccepted(invitation *entity.Invitations, at time.Time) error
+	// DeletePending deletes an unused invitation, or returns ErrNotFound.
+	DeletePending(id uint) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func (r *invitationRepository) Create(invitation *entity.Invitations) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *invitationRepository) List() ([]entity.Invitations, error) {
+	var invitations []entity.Invitations
+	err := r.db.Order("created_at desc").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) FindByTokenHash(tokenHash string) (*entity.Invitations, error) {
+	var invitation entity.Invitations
+	if err := r.db.First(&invitation, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) FindPending(email string, now time.Time) (*entity.Invitations, error) {
+	var invitation entity.Invitations
+	err := r.db.Where("email = ? AND accepted_at IS NULL AND expires_at > ?", email, now).
+		Order("created_at desc").
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) MarkAccepted(invitation *entity.Invitations, at time.Time) error {
+	result := r.db.Model(&entity.Invitations{}).
+		Where("id = ? AND accepted_at IS NULL", invitation.ID).
+		Update("accepted_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	invitation.AcceptedAt = &at
+	return nil
+}
+
+func (r *invitationRepository) DeletePending(id uint) error {
+	result := r.db.Where("accepted_at IS NULL").Delete(&entity.Invitations{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
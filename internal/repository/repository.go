@@ -0,0 +1,89 @@
+// Package repository hides persistence behind interfaces so the services
+// can run against GORM in production and against fakes in tests.
+package repository
+
+import (
+	"context"
+	"micro/internal/events"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a lookup matches no row. It is the GORM error
+// so callers checking either keep working.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Store gives access to the repositories. Repositories obtained from the
+// Store passed to a Transaction callback share that transaction.
+type Store interface {
+	Users() UserRepository
+	Sessions() SessionRepository
+	Invitations() InvitationRepository
+	ServiceClients() ServiceClientRepository
+	AuditLogs() AuditLogRepository
+	WebhookEndpoints() WebhookEndpointRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+	SigningKeys() SigningKeyRepository
+	// Emit appends a domain event to the outbox, atomically with the rest of
+	// the transaction when called inside one.
+	Emit(eventType events.Type, userID uint, payload interface{}) error
+	Transaction(fn func(tx Store) error) error
+	// WithContext returns a Store whose queries run with ctx, so that they
+	// are traced and cancelled along with it.
+	WithContext(ctx context.Context) Store
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewStore returns a Store backed by db.
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Users() UserRepository {
+	return &userRepository{db: s.db}
+}
+
+func (s *gormStore) Sessions() SessionRepository {
+	return &sessionRepository{db: s.db}
+}
+
+func (s *gormStore) Invitations() InvitationRepository {
+	return &invitationRepository{db: s.db}
+}
+
+func (s *gormStore) ServiceClients() ServiceClientRepository {
+	return &serviceClientRepository{db: s.db}
+}
+
+func (s *gormStore) AuditLogs() AuditLogRepository {
+	return &auditLogRepository{db: s.db}
+}
+
+func (s *gormStore) WebhookEndpoints() WebhookEndpointRepository {
+	return &webhookEndpointRepository{db: s.db}
+}
+
+func (s *gormStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: s.db}
+}
+
+func (s *gormStore) SigningKeys() SigningKeyRepository {
+	return &signingKeyRepository{db: s.db}
+}
+
+func (s *gormStore) Emit(eventType events.Type, userID uint, payload interface{}) error {
+	return events.Emit(s.db, eventType, userID, payload)
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+func (s *gormStore) WithContext(ctx context.Context) Store {
+	return &gormStore{db: s.db.WithContext(ctx)}
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/testutil"
+	"testing"
+	"time"
+)
+
+func newStore(t *testing.T) repository.Store {
+	t.Helper()
+	return repository.NewStore(testutil.OpenDB(t))
+}
+
+func createUser(t *testing.T, store repository.Store, email string) *entity.Users {
+	t.Helper()
+	user := &entity.Users{Email: email, Role: "member"}
+	if err := store.Users().Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func TestUserDefaults(t *testing.T) {
+	store := newStore(t)
+	user := createUser(t, store, "jane@example.com")
+
+	found, err := store.Users().FindByEmail("jane@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if found.ID != user.ID || found.Provider == nil || *found.Provider != "default" {
+		t.Fatalf("found = %+v, want id %d with default provider", found, user.ID)
+	}
+
+	if _, err := store.Users().FindByEmail("nobody@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("FindByEmail(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUserRoleIsConstrained(t *testing.T) {
+	store := newStore(t)
+	user := createUser(t, store, "jane@example.com")
+
+	if err := store.Users().UpdateRole(user.ID, "admin"); err != nil {
+		t.Fatalf("UpdateRole(admin): %v", err)
+	}
+	if err := store.Users().UpdateRole(user.ID, "owner"); err == nil {
+		t.Fatal("UpdateRole(owner) succeeded, want check constraint violation")
+	}
+}
+
+func TestUserSoftDeleteAndPurge(t *testing.T) {
+	store := newStore(t)
+	user := createUser(t, store, "jane@example.com")
+
+	if err := store.Users().Delete(user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Users().FindByID(user.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("FindByID after delete error = %v, want ErrNotFound", err)
+	}
+
+	ids, err := store.Users().ListDeletedBefore(time.Now().Add(time.Minute))
+	if err != nil || len(ids) != 1 || ids[0] != user.ID {
+		t.Fatalf("ListDeletedBefore = %v, %v; want [%d]", ids, err, user.ID)
+	}
+	purged, err := store.Users().Purge(ids)
+	if err != nil || purged != 1 {
+		t.Fatalf("Purge = %d, %v; want 1", purged, err)
+	}
+}
+
+func TestSessionRevoke(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	for _, id := range []string{"s1", "s2"} {
+		if err := store.Sessions().Create(&entity.Sessions{ID: id, UserID: 1, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+	}
+
+	if err := store.Sessions().Revoke(2, "s1", now); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Revoke(other user) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Sessions().Revoke(1, "s1", now); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := store.Sessions().Revoke(1, "s1", now); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Revoke(twice) error = %v, want ErrNotFound", err)
+	}
+
+	active, err := store.Sessions().ListActive(1, now)
+	if err != nil || len(active) != 1 || active[0].ID != "s2" {
+		t.Fatalf("ListActive = %v, %v; want [s2]", active, err)
+	}
+}
+
+func TestInvitationFindPending(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	invitation := &entity.Invitations{Email: "jane@example.com", TokenHash: "hash", Role: "admin", ExpiresAt: now.Add(time.Hour)}
+	if err := store.Invitations().Create(invitation); err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+
+	if _, err := store.Invitations().FindPending("jane@example.com", now); err != nil {
+		t.Fatalf("FindPending: %v", err)
+	}
+	stale := *invitation
+	if err := store.Invitations().MarkAccepted(invitation, now); err != nil {
+		t.Fatalf("MarkAccepted: %v", err)
+	}
+	if err := store.Invitations().MarkAccepted(&stale, now); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("MarkAccepted(accepted) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Invitations().FindPending("jane@example.com", now); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("FindPending(accepted) error = %v, want ErrNotFound", err)
+	}
+	if err := store.Invitations().DeletePending(invitation.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("DeletePending(accepted) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTransactionRollsBack(t *testing.T) {
+	db := testutil.OpenDB(t)
+	store := repository.NewStore(db)
+	errAbort := errors.New("abort")
+
+	err := store.Transaction(func(tx repository.Store) error {
+		user := createUser(t, tx, "jane@example.com")
+		if err := tx.Emit(events.UserRegistered, user.ID, events.NewUserPayload(user)); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Transaction error = %v, want %v", err, errAbort)
+	}
+
+	if _, err := store.Users().FindByEmail("jane@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("user survived the rollback: %v", err)
+	}
+	var outbox int64
+	if err := db.Model(&entity.OutboxMessages{}).Count(&outbox).Error; err != nil || outbox != 0 {
+		t.Fatalf("outbox has %d messages (%v), want 0", outbox, err)
+	}
+}
+
+func TestStoreWithContextStopsWhenCancelled(t *testing.T) {
+	store := newStore(t)
+	user := createUser(t, store, "jane@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := store.WithContext(ctx).Users().FindByID(user.ID); err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	cancel()
+	if _, err := store.WithContext(ctx).Users().FindByID(user.ID); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FindByID after cancel error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+type ServiceClientRepository interface {
+	Create(client *entity.ServiceClients) error
+	// List returns every service client, in creation order.
+	List() ([]entity.ServiceClients, error)
+	FindByClientID(clientID string) (*entity.ServiceClients, error)
+	// Delete deletes a service client, or returns ErrNotFound.
+	Delete(id uint) error
+}
+
+type serviceClientRepository struct {
+	db *gorm.DB
+}
+
+func (r *serviceClientRepository) Create(client *entity.ServiceClients) error {
+	return r.db.Create(client).Error
+}
+
+func (r *serviceClientRepository) List() ([]entity.ServiceClients, error) {
+	var clients []entity.ServiceClients
+	err := r.db.Order("id").Find(&clients).Error
+	return clients, err
+}
+
+func (r *serviceClientRepository) FindByClientID(clientID string) (*entity.ServiceClients, error) {
+	var client entity.ServiceClients
+	if err := r.db.First(&client, "client_id = ?", clientID).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *serviceClientRepository) Delete(id uint) error {
+	result := r.db.Delete(&entity.ServiceClients{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *entity.Sessions) error
+	// FindForUser returns the session only if it belongs to userID.
+	FindForUser(id string, userID uint) (*entity.Sessions, error)
+	// ListActive returns the sessions neither revoked nor expired at now,
+	// most recently used first.
+	ListActive(userID uint, now time.Time) ([]entity.Sessions, error)
+	// ListAll returns every session of the user, newest first.
+	ListAll(userID uint) ([]entity.Sessions, error)
+	// Revoke revokes one active session of the user, or returns ErrNotFound.
+	Revoke(userID uint, id string, at time.Time) error
+	RevokeAll(userID uint, at time.Time) error
+	Touch(id string, at time.Time) error
+	DeleteForUsers(userIDs []uint) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *sessionRepository) Create(session *entity.Sessions) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindForUser(id string, userID uint) (*entity.Sessions, error) {
+	var session entity.Sessions
+	if err := r.db.First(&session, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) ListActive(userID uint, now time.Time) ([]entity.Sessions, error) {
+	var sessions []entity.Sessions
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) ListAll(userID uint) ([]entity.Sessions, error) {
+	var sessions []entity.Sessions
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) Revoke(userID uint, id string, at time.Time) error {
+	result := r.db.Model(&entity.Sessions{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(userID uint, at time.Time) error {
+	return r.db.Model(&entity.Sessions{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *sessionRepository) Touch(id string, at time.Time) error {
+	return r.db.Model(&entity.Sessions{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+func (r *sessionRepository) DeleteForUsers(userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return r.db.Where("user_id IN ?", userIDs).Delete(&entity.Sessions{}).Error
+}
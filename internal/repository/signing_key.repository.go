@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository interface {
+	// ListUsable returns the active key and the keys rotated after
+	// rotatedAfter, newest first.
+	ListUsable(rotatedAfter time.Time) ([]entity.SigningKeys, error)
+	// Create stores a key. Storing a second active key fails on the unique
+	// active slot.
+	Create(key *entity.SigningKeys) error
+	// Deactivate demotes the active key to verification only, as rotated at
+	// at.
+	Deactivate(at time.Time) error
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *signingKeyRepository) ListUsable(rotatedAfter time.Time) ([]entity.SigningKeys, error) {
+	var keys []entity.SigningKeys
+	err := r.db.
+		Where("active = ? OR rotated_at > ?", true, rotatedAfter).
+		Order("created_at desc").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *signingKeyRepository) Create(key *entity.SigningKeys) error {
+	return r.db.Create(key).Error
+}
+
+func (r *signingKeyRepository) Deactivate(at time.Time) error {
+	return r.db.Model(&entity.SigningKeys{}).
+		Where("active = ?", true).
+		Updates(map[string]interface{}{"active": false, "active_slot": nil, "rotated_at": at}).Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	FindByID(id uint) (*entity.Users, error)
+	FindByEmail(email string) (*entity.Users, error)
+	// FindByIDs returns the existing users among ids, ordered by id.
+	FindByIDs(ids []uint) ([]entity.Users, error)
+	// List returns every user that is not deleted, ordered by id.
+	List() ([]entity.Users, error)
+	Create(user *entity.Users) error
+	Save(user *entity.Users) error
+	UpdateRole(id uint, role string) error
+	// Delete soft-deletes the user.
+	Delete(id uint) error
+	// ListDeletedBefore returns the ids of users soft-deleted before cutoff.
+	ListDeletedBefore(cutoff time.Time) ([]uint, error)
+	// Purge permanently removes the users and returns how many were removed.
+	Purge(ids []uint) (int64, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func (r *userRepository) FindByID(id uint) (*entity.Users, error) {
+	var user entity.Users
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*entity.Users, error) {
+	var user entity.Users
+	if err := r.db.First(&user, "email = ?", email).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByIDs(ids []uint) ([]entity.Users, error) {
+	var users []entity.Users
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) List() ([]entity.Users, error) {
+	var users []entity.Users
+	err := r.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Create(user *entity.Users) error {
+	return r.db.Create(user).Error
+}
+
+func (r *userRepository) Save(user *entity.Users) error {
+	return r.db.Save(user).Error
+}
+
+func (r *userRepository) UpdateRole(id uint, role string) error {
+	return r.db.Model(&entity.Users{}).Where("id = ?", id).Update("role", role).Error
+}
+
+func (r *userRepository) Delete(id uint) error {
+	return r.db.Delete(&entity.Users{}, id).Error
+}
+
+func (r *userRepository) ListDeletedBefore(cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&entity.Users{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *userRepository) Purge(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Unscoped().Delete(&entity.Users{}, ids)
+	return result.RowsAffected, result.Error
+}
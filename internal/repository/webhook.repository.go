@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"micro/internal/models/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookEndpointRepository interface {
+	Create(endpoint *entity.WebhookEndpoints) error
+	// List returns every endpoint, in creation order.
+	List() ([]entity.WebhookEndpoints, error)
+	FindByID(id uint) (*entity.WebhookEndpoints, error)
+	Save(endpoint *entity.WebhookEndpoints) error
+	// Delete soft-deletes an endpoint, or returns ErrNotFound.
+	Delete(id uint) error
+}
+
+type WebhookDeliveryRepository interface {
+	// Enqueue stores deliveries, skipping those of an endpoint that already
+	// has a delivery of the same event.
+	Enqueue(deliveries []entity.WebhookDeliveries) error
+	FindByID(id uint) (*entity.WebhookDeliveries, error)
+	// ListByEndpoint returns up to limit deliveries of an endpoint, newest
+	// first, skipping the first offset, along with the number of deliveries.
+	ListByEndpoint(endpointID uint, offset, limit int) ([]entity.WebhookDeliveries, int64, error)
+	// ListDue returns up to limit pending deliveries due at now, the longest
+	// due first.
+	ListDue(now time.Time, limit int) ([]entity.WebhookDeliveries, error)
+	// Lease postpones the next attempt of a pending delivery due at now to
+	// until. It reports false when the delivery is no longer due, because
+	// another dispatcher leased it first.
+	Lease(id uint, now, until time.Time) (bool, error)
+	Save(delivery *entity.WebhookDeliveries) error
+}
+
+type webhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+func (r *webhookEndpointRepository) Create(endpoint *entity.WebhookEndpoints) error {
+	return r.db.Create(endpoint).Error
+}
+
+func (r *webhookEndpointRepository) List() ([]entity.WebhookEndpoints, error) {
+	var endpoints []entity.WebhookEndpoints
+	err := r.db.Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookEndpointRepository) FindByID(id uint) (*entity.WebhookEndpoints, error) {
+	var endpoint entity.WebhookEndpoints
+	if err := r.db.First(&endpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookEndpointRepository) Save(endpoint *entity.WebhookEndpoints) error {
+	return r.db.Save(endpoint).Error
+}
+
+func (r *webhookEndpointRepository) Delete(id uint) error {
+	result := r.db.Delete(&entity.WebhookEndpoints{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func (r *webhookDeliveryRepository) Enqueue(deliveries []entity.WebhookDeliveries) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+func (r *webhookDeliveryRepository) FindByID(id uint) (*entity.WebhookDeliveries, error) {
+	var delivery entity.WebhookDeliveries
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) ListByEndpoint(endpointID uint, offset, limit int) ([]entity.WebhookDeliveries, int64, error) {
+	query := r.db.Model(&entity.WebhookDeliveries{}).Where("endpoint_id = ?", endpointID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []entity.WebhookDeliveries
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *webhookDeliveryRepository) ListDue(now time.Time, limit int) ([]entity.WebhookDeliveries, error) {
+	var deliveries []entity.WebhookDeliveries
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookDeliveryRepository) Lease(id uint, now, until time.Time) (bool, error) {
+	result := r.db.Model(&entity.WebhookDeliveries{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, entity.WebhookDeliveryPending, now).
+		Update("next_attempt_at", until)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *webhookDeliveryRepository) Save(delivery *entity.WebhookDeliveries) error {
+	return r.db.Save(delivery).Error
+}
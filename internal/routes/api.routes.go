@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/metrics"
+	"micro/internal/middleware"
+	"micro/internal/openapi"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unversionedDeprecatedSince is when /api/v1 replaced the unversioned paths.
+var unversionedDeprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// AllRoutes mounts every route of the HTTP server. The docs are mounted
+// before the API so that they do not get the deprecation headers of the
+// unversioned /api alias.
+func AllRoutes(app *fiber.App, h *handlers.Handler, mw *middleware.Middleware, m *metrics.Metrics, doc *openapi.Document) {
+	HealthRoutes(app, h)
+	MetricsRoutes(app, m)
+	WellKnownRoutes(app, h)
+	DocsRoutes(app, doc)
+	APIRoutes(app, h, mw)
+}
+
+// APIRoutes mounts every version of the API under /api/<version>. The
+// response shapes of a version never change once released: a breaking
+// change goes into a new version with its own route function, which reuses
+// the handlers of the endpoints it keeps and is mounted next to the older
+// ones until they are retired.
+func APIRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	V1Routes(router.Group("/api/v1"), h, mw)
+
+	// The unversioned paths predate /api/v1 and serve it for the clients
+	// and introspection URLs configured before it existed.
+	V1Routes(router.Group("/api", middleware.Deprecated("/api/v1", unversionedDeprecatedSince)), h, mw)
+}
+
+// V1Routes registers the endpoints of API v1.
+func V1Routes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	AuthRoutes(router, h, mw)
+	InvitationRoutes(router, h, mw)
+	UserRoutes(router, h, mw)
+	AuditRoutes(router, h, mw)
+	WebhookRoutes(router, h, mw)
+	OAuthRoutes(router, h, mw)
+}
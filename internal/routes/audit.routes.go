@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AuditRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Get("/audit-logs", mw.Auth, middleware.AdminRole, h.ListAuditLogs)
+}
@@ -2,17 +2,19 @@ package routes
 
 import (
 	"micro/internal/handlers"
+	"micro/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthRoutes(router fiber.Router) {
-	router.Post("/auth/login", handlers.Login)
-	router.Post("/auth/register", handlers.Register)
+func AuthRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Post("/auth/login", h.Login)
+	router.Post("/auth/register", h.Register)
+	router.Post("/auth/logout", mw.Auth, h.Logout)
 
-  router.Get("/auth/google", handlers.AuthGoogle)
-  router.Get("/auth/google/callback", handlers.CallbackAuthGoogle)
+	router.Get("/auth/google", h.AuthGoogle)
+	router.Get("/auth/google/callback", h.CallbackAuthGoogle)
 
-  router.Get("/auth/github", handlers.AuthGithub)
-  router.Get("/auth/github/callback", handlers.CallbackAuthGithub)
+	router.Get("/auth/github", h.AuthGithub)
+	router.Get("/auth/github/callback", h.CallbackAuthGithub)
 }
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"micro/internal/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DocsRoutes(router fiber.Router, doc *openapi.Document) {
+	router.Get(openapi.SpecPath, doc.Handler())
+	router.Get(openapi.DocsPath, openapi.UIHandler(openapi.SpecPath))
+	router.Get(openapi.AssetPath, openapi.AssetHandler())
+}
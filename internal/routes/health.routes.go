@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func HealthRoutes(router fiber.Router, h *handlers.Handler) {
+	router.Get("/healthz", h.Healthz)
+	router.Get("/readyz", h.Readyz)
+}
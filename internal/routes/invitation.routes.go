@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InvitationRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Post("/invitations/accept", h.AcceptInvitation)
+
+	router.Get("/invitations", mw.Auth, middleware.AdminRole, h.ListInvitations)
+	router.Post("/invitations", mw.Auth, middleware.AdminRole, h.CreateInvitation)
+	router.Delete("/invitations/:id", mw.Auth, middleware.AdminRole, h.RevokeInvitation)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"micro/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MetricsRoutes(router fiber.Router, m *metrics.Metrics) {
+	router.Get("/metrics", m.Handler())
+}
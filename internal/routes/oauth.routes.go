@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func OAuthRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Post("/oauth/introspect", h.IntrospectToken)
+
+	router.Get("/service-clients", mw.Auth, middleware.AdminRole, h.ListServiceClients)
+	router.Post("/service-clients", mw.Auth, middleware.AdminRole, h.CreateServiceClient)
+	router.Delete("/service-clients/:id", mw.Auth, middleware.AdminRole, h.DeleteServiceClient)
+}
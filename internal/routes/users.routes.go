@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func UserRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Delete("/users/me", mw.Auth, h.DeleteAccount)
+	router.Get("/users/me/export", mw.Auth, h.ExportUserData)
+
+	router.Get("/users/me/sessions", mw.Auth, h.ListSessions)
+	router.Delete("/users/me/sessions/:id", mw.Auth, h.RevokeSession)
+	router.Get("/users/me/security-activity", mw.Auth, h.SecurityActivity)
+
+	router.Put("/users/:id/role", mw.Auth, middleware.AdminRole, h.ChangeUserRole)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+	"micro/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WebhookRoutes(router fiber.Router, h *handlers.Handler, mw *middleware.Middleware) {
+	router.Get("/webhooks", mw.Auth, middleware.AdminRole, h.ListWebhooks)
+	router.Post("/webhooks", mw.Auth, middleware.AdminRole, h.CreateWebhook)
+	router.Put("/webhooks/:id", mw.Auth, middleware.AdminRole, h.UpdateWebhook)
+	router.Delete("/webhooks/:id", mw.Auth, middleware.AdminRole, h.DeleteWebhook)
+	router.Get("/webhooks/:id/deliveries", mw.Auth, middleware.AdminRole, h.ListWebhookDeliveries)
+	router.Post("/webhooks/deliveries/:id/redeliver", mw.Auth, middleware.AdminRole, h.RedeliverWebhook)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"micro/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WellKnownRoutes(router fiber.Router, h *handlers.Handler) {
+	router.Get("/.well-known/jwks.json", h.JWKS)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"micro/internal/apperr"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/repository"
+	"micro/internal/validation"
+	"time"
+)
+
+// reauthWindow is how recent a login must be to delete an account that has
+// no password to confirm, such as one created through an OAuth provider.
+const reauthWindow = 10 * time.Minute
+
+var (
+	ErrInvalidPassword          = apperr.New(apperr.Unauthenticated, "invalid_password", "Invalid password")
+	ErrReauthenticationRequired = apperr.New(apperr.Forbidden, "reauthentication_required", "Please log in again before deleting your account")
+)
+
+func (s *AuthService) GetUserByID(ctx context.Context, id uint) (*entity.Users, error) {
+	return s.store.WithContext(ctx).Users().FindByID(id)
+}
+
+// DeleteAccount re-authenticates the user and soft-deletes the account,
+// revoking every session. The row is purged once the grace period is over.
+func (s *AuthService) DeleteAccount(ctx context.Context, userID uint, sessionID, password string) error {
+	store := s.store.WithContext(ctx)
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password != "" {
+		if !s.checkPassword(ctx, user.Password, password) {
+			return ErrInvalidPassword
+		}
+	} else {
+		session, err := store.Sessions().FindForUser(sessionID, user.ID)
+		if err != nil {
+			return err
+		}
+		if s.now().Sub(session.CreatedAt) > reauthWindow {
+			return ErrReauthenticationRequired
+		}
+	}
+
+	return store.Transaction(func(tx repository.Store) error {
+		if err := tx.Sessions().RevokeAll(user.ID, s.now()); err != nil {
+			return err
+		}
+		if err := tx.Users().Delete(user.ID); err != nil {
+			return err
+		}
+		return tx.Emit(events.UserDeleted, user.ID, events.UserDeletedPayload{
+			UserID:     user.ID,
+			Email:      user.Email,
+			PurgeAfter: s.now().Add(s.gracePeriod),
+		})
+	})
+}
+
+// PurgeDeletedAccounts permanently removes accounts soft-deleted before the
+// cutoff, together with their sessions.
+func (s *AuthService) PurgeDeletedAccounts(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		ids, err := tx.Users().ListDeletedBefore(cutoff)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Sessions().DeleteForUsers(ids); err != nil {
+			return err
+		}
+
+		purged, err = tx.Users().Purge(ids)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := tx.Emit(events.UserPurged, id, events.UserPurgedPayload{UserID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// StartAccountPurger runs PurgeDeletedAccounts every interval until ctx is
+// done, purging accounts deleted more than the grace period ago.
+func (s *AuthService) StartAccountPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeDeletedAccounts(ctx, s.now().Add(-s.gracePeriod))
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to purge deleted accounts", "error", err)
+			} else if purged > 0 {
+				s.logger.InfoContext(ctx, "purged deleted accounts", "count", purged)
+			}
+		}
+	}
+}
+
+// ListAllUserSessions returns every session of a user, including revoked and
+// expired ones.
+func (s *AuthService) ListAllUserSessions(ctx context.Context, userID uint) ([]entity.Sessions, error) {
+	return s.store.WithContext(ctx).Sessions().ListAll(userID)
+}
+
+func ValidateChangeRole(changeRoleRequest *request.ChangeRoleRequest) error {
+	return validation.Struct(changeRoleRequest)
+}
+
+// ChangeUserRole sets the role of a user and returns the previous one.
+func (s *AuthService) ChangeUserRole(ctx context.Context, userID uint, role string) (string, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	previousRole := user.Role
+	if previousRole == role {
+		return previousRole, nil
+	}
+
+	err = s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		if err := tx.Users().UpdateRole(user.ID, role); err != nil {
+			return err
+		}
+		return tx.Emit(events.RoleChanged, user.ID, events.RoleChangedPayload{
+			UserID:       user.ID,
+			PreviousRole: previousRole,
+			Role:         role,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return previousRole, nil
+}
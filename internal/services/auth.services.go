@@ -2,63 +2,116 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"micro/config"
-	"micro/internal/middleware"
+	"micro/internal/apperr"
+	"micro/internal/events"
+	"micro/internal/metrics"
 	"micro/internal/models/entity"
 	"micro/internal/models/request"
 	"micro/internal/provider"
+	"micro/internal/repository"
+	"micro/internal/tracing"
 	"micro/internal/utils"
-	"net/http"
+	"micro/internal/validation"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
-	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
+var (
+	ErrUnknownProvider    = apperr.New(apperr.NotFound, "unknown_provider", "Unknown OAuth provider")
+	ErrOAuthExchange      = apperr.New(apperr.Unauthenticated, "oauth_exchange_failed", "Failed to exchange authorization code for token")
+	ErrOAuthEmailMissing  = apperr.New(apperr.Invalid, "oauth_email_missing", "Email is missing from user info")
+	ErrProviderMismatch   = apperr.New(apperr.Conflict, "provider_mismatch", "Account is registered with another provider")
+	ErrInvalidCredentials = apperr.New(apperr.Unauthenticated, "invalid_credentials", "Invalid email or password")
+)
+
+// AuthService holds the account, session and token logic. Its dependencies
+// are injected so that it runs against fakes in tests.
+type AuthService struct {
+	store        repository.Store
+	jwt          *utils.JWT
+	providers    map[string]provider.Provider
+	registration config.RegistrationConfig
+	gracePeriod  time.Duration
+	oauthTimeout time.Duration
+	logger       *slog.Logger
+	metrics      *metrics.Metrics
+	now          func() time.Time
+}
+
+func NewAuthService(store repository.Store, jwt *utils.JWT, cfg *config.Config, logger *slog.Logger, m *metrics.Metrics, providers ...provider.Provider) *AuthService {
+	s := &AuthService{
+		store:        store,
+		jwt:          jwt,
+		providers:    make(map[string]provider.Provider, len(providers)),
+		registration: cfg.Registration,
+		gracePeriod:  cfg.Account.PurgeGracePeriod,
+		oauthTimeout: cfg.OAuth.Timeout,
+		logger:       logger,
+		metrics:      m,
+		now:          time.Now,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
+}
+
 func ValidateLogin(loginRequest *request.LoginRequest) error {
-	validate := validator.New()
-	return validate.Struct(loginRequest)
+	return validation.Struct(loginRequest)
 }
 
-func GetUserByEmail(email string) (*entity.Users, error) {
-	var user entity.Users
-	err := config.DB.First(&user, "email = ?", email).Error
-	return &user, err
+func (s *AuthService) GetUserByEmail(ctx context.Context, email string) (*entity.Users, error) {
+	return s.store.WithContext(ctx).Users().FindByEmail(email)
 }
 
-func GenerateJWTToken(user *entity.Users) (string, error) {
-	claims := jwt.MapClaims{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"exp":   time.Now().Add(time.Hour * 24 * 7).Unix(),
-		"role":  "member",
+func (s *AuthService) GenerateJWTToken(user *entity.Users, session *entity.Sessions) (string, error) {
+	claims := utils.Claims{
+		UserID: user.ID,
+		Name:   user.Name,
+		Email:  user.Email,
+		Role:   "member",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        session.ID,
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+		},
 	}
 
 	if user.Role == "admin" {
-		claims["role"] = "admin"
+		claims.Role = "admin"
 	}
 
-	return utils.GenerateToken(&claims)
+	return s.jwt.GenerateToken(&claims)
 }
 
 func ValidateRegister(registerRequest *request.RegisterRequest) error {
-	validate := validator.New()
-	return validate.Struct(registerRequest)
+	return validation.Struct(registerRequest)
 }
 
-func HashAndStoreUser(registerRequest *request.RegisterRequest) (string, error) {
-	var existingUser entity.Users
-	if err := config.DB.First(&existingUser, "email = ?", registerRequest.Email).Error; err == nil {
-		return "", fmt.Errorf("user with email %s already exists", registerRequest.Email)
+func (s *AuthService) HashAndStoreUser(ctx context.Context, registerRequest *request.RegisterRequest) (user *entity.Users, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.HashAndStoreUser")
+	defer func() { tracing.End(span, err) }()
+	store := s.store.WithContext(ctx)
+
+	if err := s.CheckRegistrationAllowed(registerRequest.Email); err != nil {
+		return nil, err
+	}
+
+	if _, err := store.Users().FindByEmail(registerRequest.Email); err == nil {
+		return nil, ErrUserAlreadyExists
 	}
 
-	hashedPassword, err := middleware.HashPassword(registerRequest.Password)
+	hashedPassword, err := s.hashPassword(ctx, registerRequest.Password)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	newUser := entity.Users{
@@ -71,143 +124,206 @@ func HashAndStoreUser(registerRequest *request.RegisterRequest) (string, error)
 		Verify:    true,
 	}
 
-	if err := config.DB.Create(&newUser).Error; err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("User %s registered successfully", newUser.Email), nil
-}
-
-func UpdateUser(user *entity.Users) error {
-	return config.DB.Save(user).Error
-}
-
-func AuthenticateUser(email, password string) (*entity.Users, error) {
-	var user entity.Users
-	err := config.DB.First(&user, "email = ?", email).Error
+	err = store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Create(&newUser); err != nil {
+			return err
+		}
+		return tx.Emit(events.UserRegistered, newUser.ID, events.NewUserPayload(&newUser))
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !middleware.CheckPassword(user.Password, password) {
-		return nil, fmt.Errorf("invalid password")
-	}
-
-	return &user, nil
+	return &newUser, nil
 }
 
-func GetGoogleAuthURL(redirectURI string) string {
-	return provider.GoogleOauthConfig.AuthCodeURL(redirectURI)
-}
+// UpdateUser saves user and emits events for the email and verification
+// changes it contains.
+func (s *AuthService) UpdateUser(ctx context.Context, user *entity.Users) error {
+	return s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		stored, err := tx.Users().FindByID(user.ID)
+		if err != nil {
+			return err
+		}
 
-func GetGithubAuthUrl(redirectURI string) string {
-	return provider.GithubOauthConfig.AuthCodeURL(redirectURI)
+		if err := tx.Users().Save(user); err != nil {
+			return err
+		}
+
+		if stored.Email != user.Email {
+			if err := tx.Emit(events.UserEmailChanged, user.ID, events.EmailChangedPayload{
+				UserID:        user.ID,
+				PreviousEmail: stored.Email,
+				Email:         user.Email,
+			}); err != nil {
+				return err
+			}
+		}
+		if !stored.Verify && user.Verify {
+			if err := tx.Emit(events.UserVerified, user.ID, events.NewUserPayload(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func GetGoogleUserInfo(token *oauth2.Token) (map[string]interface{}, error) {
-	client := provider.GoogleOauthConfig.Client(context.Background(), token)
+func (s *AuthService) AuthenticateUser(ctx context.Context, email, password string) (user *entity.Users, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.AuthenticateUser")
+	defer func() { tracing.End(span, err) }()
 
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
+	user, err = s.store.WithContext(ctx).Users().FindByEmail(email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get user info: status code %d", resp.StatusCode)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
 	}
 
-	var userInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	if !s.checkPassword(ctx, user.Password, password) {
+		return nil, ErrInvalidCredentials
 	}
 
-	return userInfo, nil
+	return user, nil
 }
 
-func GetGithubUserInfo(token *oauth2.Token) (map[string]interface{}, error) {
-	client := provider.GithubOauthConfig.Client(context.Background(), token)
-
-	resp, err := client.Get("https://api.github.com/user")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+// AuthURL returns the consent page of the named provider. state is handed
+// back to the callback.
+func (s *AuthService) AuthURL(providerName, state string) (string, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
 	}
-	defer resp.Body.Close()
+	return p.AuthCodeURL(state), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get user info: status code %d", resp.StatusCode)
+// OAuthLogin completes an OAuth flow: it exchanges code, fetches the
+// profile and returns the matching user, creating it on first login. created
+// reports whether the account was just created. On ErrProviderMismatch the
+// existing user is returned along with the error.
+func (s *AuthService) OAuthLogin(ctx context.Context, providerName, code string) (user *entity.Users, created bool, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.OAuthLogin", trace.WithAttributes(attribute.String("oauth.provider", providerName)))
+	defer func() { tracing.End(span, err) }()
+
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, false, ErrUnknownProvider
 	}
 
-	var userInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	token, err := s.exchange(ctx, p, code)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
 	}
 
-	return userInfo, nil
-}
-
-func GetGithubUserPrimaryEmail(token *oauth2.Token) (string, error) {
-	client := provider.GithubOauthConfig.Client(context.Background(), token)
-
-	resp, err := client.Get("https://api.github.com/user/emails")
+	info, err := s.userInfo(ctx, p, token)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user emails: %w", err)
+		return nil, false, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get user emails: status code %d", resp.StatusCode)
+	if info.Email == "" {
+		return nil, false, ErrOAuthEmailMissing
 	}
 
-	var emails []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
-		return "", fmt.Errorf("failed to decode user emails: %w", err)
-	}
+	store := s.store.WithContext(ctx)
 
-	for _, e := range emails {
-		if primary, ok := e["primary"].(bool); ok && primary {
-			if email, ok := e["email"].(string); ok {
-				return email, nil
-			}
+	user, err = store.Users().FindByEmail(info.Email)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		user, err = s.saveProviderUser(store, providerName, info)
+		if err != nil {
+			return nil, false, err
 		}
+		return user, true, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to check if user exists: %w", err)
 	}
 
-	return "", fmt.Errorf("no primary email found")
+	if user.Provider != nil && *user.Provider != providerName {
+		return user, false, ErrProviderMismatch
+	}
+	return user, false, nil
 }
 
-func SaveGoogleUser(firstName, lastName, email string) error {
-	provider := "google"
+// saveProviderUser creates an account for a first OAuth login. It goes through
+// the same registration policy as Register, except that a pending invitation
+// for the email lets the account through and assigns the invited role.
+func (s *AuthService) saveProviderUser(store repository.Store, providerName string, info *provider.UserInfo) (*entity.Users, error) {
 	newUser := entity.Users{
-		Name:      fmt.Sprintf("%s %s", firstName, lastName),
-		FirstName: firstName,
-		LastName:  lastName,
-		Email:     email,
+		Name:      fmt.Sprintf("%s %s", info.FirstName, info.LastName),
+		FirstName: info.FirstName,
+		LastName:  info.LastName,
+		Email:     info.Email,
 		Role:      "member",
 		Verify:    true,
-		Provider:  &provider,
+		Provider:  &providerName,
 	}
 
-	if err := config.DB.Create(&newUser).Error; err != nil {
-		return err
+	err := store.Transaction(func(tx repository.Store) error {
+		invitation, err := tx.Invitations().FindPending(strings.ToLower(info.Email), s.now())
+		switch {
+		case err == nil:
+			if s.registration.Mode == config.RegistrationClosed {
+				return ErrRegistrationClosed
+			}
+			if err := markAccepted(tx, invitation, s.now()); err != nil {
+				return err
+			}
+			newUser.Role = invitation.Role
+		case errors.Is(err, repository.ErrNotFound):
+			if err := s.CheckRegistrationAllowed(info.Email); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if err := tx.Users().Create(&newUser); err != nil {
+			return err
+		}
+		return tx.Emit(events.UserRegistered, newUser.ID, events.NewUserPayload(&newUser))
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &newUser, nil
 }
 
-func SaveGithubUser(fristName, lastName, email string) error {
-	provider := "github"
-	newUser := entity.Users{
-		Name:      fmt.Sprintf("%s %s", fristName, lastName),
-		FirstName: fristName,
-		LastName:  lastName,
-		Email:     email,
-		Role:      "member",
-		Verify:    true,
-		Provider:  &provider,
-	}
+// hashPassword and checkPassword time bcrypt, which dominates the latency of
+// the endpoints that use it.
+func (s *AuthService) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.hash")
+	defer span.End()
 
-	if err := config.DB.Create(&newUser).Error; err != nil {
-		return err
-	}
+	start := time.Now()
+	hashed, err := utils.HashPassword(password)
+	s.metrics.PasswordHash("hash", time.Since(start))
+	return hashed, err
+}
+
+func (s *AuthService) checkPassword(ctx context.Context, hashedPassword, password string) bool {
+	_, span := tracing.Start(ctx, "bcrypt.compare")
+	defer span.End()
+
+	start := time.Now()
+	ok := utils.CheckPassword(hashedPassword, password)
+	s.metrics.PasswordHash("compare", time.Since(start))
+	return ok
+}
+
+// exchange and userInfo bound the provider calls with the OAuth timeout and
+// wrap them in spans of their own, so that a slow callback shows which one
+// took the time.
+func (s *AuthService) exchange(ctx context.Context, p provider.Provider, code string) (token *oauth2.Token, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.oauthTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "oauth.Exchange", trace.WithAttributes(attribute.String("oauth.provider", p.Name())))
+	defer func() { tracing.End(span, err) }()
+	return p.Exchange(ctx, code)
+}
 
-	return nil
+func (s *AuthService) userInfo(ctx context.Context, p provider.Provider, token *oauth2.Token) (info *provider.UserInfo, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.oauthTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "oauth.UserInfo", trace.WithAttributes(attribute.String("oauth.provider", p.Name())))
+	defer func() { tracing.End(span, err) }()
+	return p.UserInfo(ctx, token)
 }
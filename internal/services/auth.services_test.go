@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/provider"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// hangingProvider never answers before its context is done.
+type hangingProvider struct{}
+
+func (hangingProvider) Name() string                    { return "google" }
+func (hangingProvider) AuthCodeURL(state string) string { return "" }
+
+func (hangingProvider) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hangingProvider) UserInfo(ctx context.Context, token *oauth2.Token) (*provider.UserInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestOAuthLoginTimesOut(t *testing.T) {
+	cfg := config.Default()
+	cfg.OAuth.Timeout = 20 * time.Millisecond
+	auth := services.NewAuthService(repository.NewStore(testutil.OpenDB(t)), utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil, hangingProvider{})
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := auth.OAuthLogin(context.Background(), "google", "code")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, services.ErrOAuthExchange) {
+			t.Fatalf("OAuthLogin error = %v, want ErrOAuthExchange", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OAuthLogin ignored the OAuth timeout")
+	}
+}
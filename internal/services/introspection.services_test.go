@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateServiceClient(t *testing.T) {
+	cfg := config.Default()
+	db := testutil.OpenDB(t)
+	auth := services.NewAuthService(repository.NewStore(db), utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil)
+	clients := services.NewIntrospectionService(repository.NewStore(db), auth, time.Minute)
+	ctx := context.Background()
+
+	client, secret, err := clients.CreateServiceClient(ctx, "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.SecretHash == secret || len(client.SecretHash) != 64 {
+		t.Errorf("secret hash = %q, want the hex SHA-256 of the secret", client.SecretHash)
+	}
+	if _, err := clients.AuthenticateServiceClient(ctx, client.ClientID, secret); err != nil {
+		t.Errorf("valid credentials rejected: %v", err)
+	}
+	if _, err := clients.AuthenticateServiceClient(ctx, client.ClientID, secret+"x"); !errors.Is(err, services.ErrInvalidClientCredentials) {
+		t.Errorf("wrong secret error = %v, want ErrInvalidClientCredentials", err)
+	}
+	if _, err := clients.AuthenticateServiceClient(ctx, "svc_unknown", secret); !errors.Is(err, services.ErrInvalidClientCredentials) {
+		t.Errorf("unknown client error = %v, want ErrInvalidClientCredentials", err)
+	}
+
+	if err := clients.DeleteServiceClient(ctx, client.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clients.AuthenticateServiceClient(ctx, client.ClientID, secret); !errors.Is(err, services.ErrInvalidClientCredentials) {
+		t.Errorf("deleted client error = %v, want ErrInvalidClientCredentials", err)
+	}
+	if err := clients.DeleteServiceClient(ctx, client.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second delete error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"micro/config"
+	"micro/internal/apperr"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/repository"
+	"micro/internal/validation"
+	"strings"
+	"time"
+)
+
+const defaultInvitationTTL = 72 * time.Hour
+
+var (
+	ErrRegistrationClosed    = apperr.New(apperr.Forbidden, "registration_closed", "Registration is closed")
+	ErrInvitationRequired    = apperr.New(apperr.Forbidden, "invitation_required", "Registration requires an invitation")
+	ErrEmailDomainNotAllowed = apperr.New(apperr.Forbidden, "email_domain_not_allowed", "Email domain is not allowed to register")
+	ErrInvitationInvalid     = apperr.New(apperr.Invalid, "invitation_invalid", "Invitation is invalid or has expired")
+	ErrUserAlreadyExists     = apperr.New(apperr.Conflict, "email_taken", "Email already in use")
+)
+
+// IsRegistrationDenied reports whether err was caused by the registration policy.
+func IsRegistrationDenied(err error) bool {
+	return errors.Is(err, ErrRegistrationClosed) ||
+		errors.Is(err, ErrInvitationRequired) ||
+		errors.Is(err, ErrEmailDomainNotAllowed)
+}
+
+// CheckRegistrationAllowed applies the configured registration mode to a
+// self-service sign up that is not backed by an invitation.
+func (s *AuthService) CheckRegistrationAllowed(email string) error {
+	switch s.registration.Mode {
+	case config.RegistrationClosed:
+		return ErrRegistrationClosed
+	case config.RegistrationInviteOnly:
+		return ErrInvitationRequired
+	case config.RegistrationDomain:
+		if !s.emailDomainAllowed(email) {
+			return ErrEmailDomainNotAllowed
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) emailDomainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range s.registration.AllowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateCreateInvitation(invitationRequest *request.CreateInvitationRequest) error {
+	return validation.Struct(invitationRequest)
+}
+
+func ValidateAcceptInvitation(acceptRequest *request.AcceptInvitationRequest) error {
+	return validation.Struct(acceptRequest)
+}
+
+// CreateInvitation stores a new invitation and returns it together with the
+// plain token, which is only ever available at creation time.
+func (s *AuthService) CreateInvitation(ctx context.Context, invitationRequest *request.CreateInvitationRequest, invitedBy uint) (*entity.Invitations, string, error) {
+	store := s.store.WithContext(ctx)
+
+	if s.registration.Mode == config.RegistrationClosed {
+		return nil, "", ErrRegistrationClosed
+	}
+
+	if _, err := store.Users().FindByEmail(invitationRequest.Email); err == nil {
+		return nil, "", ErrUserAlreadyExists
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := invitationRequest.Role
+	if role == "" {
+		role = "member"
+	}
+
+	ttl := defaultInvitationTTL
+	if invitationRequest.ExpiresInHours > 0 {
+		ttl = time.Duration(invitationRequest.ExpiresInHours) * time.Hour
+	}
+
+	invitation := entity.Invitations{
+		Email:     strings.ToLower(invitationRequest.Email),
+		TokenHash: hashInvitationToken(token),
+		Role:      role,
+		InvitedBy: invitedBy,
+		ExpiresAt: s.now().Add(ttl),
+	}
+
+	if err := store.Invitations().Create(&invitation); err != nil {
+		return nil, "", err
+	}
+
+	return &invitation, token, nil
+}
+
+func (s *AuthService) ListInvitations(ctx context.Context) ([]entity.Invitations, error) {
+	return s.store.WithContext(ctx).Invitations().List()
+}
+
+func (s *AuthService) RevokeInvitation(ctx context.Context, id uint) error {
+	return s.store.WithContext(ctx).Invitations().DeletePending(id)
+}
+
+// AcceptInvitation creates the invited account with the role assigned by the
+// admin and marks the invitation as used.
+func (s *AuthService) AcceptInvitation(ctx context.Context, acceptRequest *request.AcceptInvitationRequest) (*entity.Users, error) {
+	if s.registration.Mode == config.RegistrationClosed {
+		return nil, ErrRegistrationClosed
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, acceptRequest.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var newUser entity.Users
+	err = s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		invitation, err := tx.Invitations().FindByTokenHash(hashInvitationToken(acceptRequest.Token))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvitationInvalid
+			}
+			return err
+		}
+		if invitation.AcceptedAt != nil || s.now().After(invitation.ExpiresAt) {
+			return ErrInvitationInvalid
+		}
+		if err := markAccepted(tx, invitation, s.now()); err != nil {
+			return err
+		}
+
+		if _, err := tx.Users().FindByEmail(invitation.Email); err == nil {
+			return ErrUserAlreadyExists
+		}
+
+		newUser = entity.Users{
+			Name:      fmt.Sprintf("%s %s", acceptRequest.FirstName, acceptRequest.LastName),
+			FirstName: acceptRequest.FirstName,
+			LastName:  acceptRequest.LastName,
+			Email:     invitation.Email,
+			Password:  hashedPassword,
+			Role:      invitation.Role,
+			Verify:    true,
+		}
+		if err := tx.Users().Create(&newUser); err != nil {
+			return err
+		}
+		return tx.Emit(events.UserRegistered, newUser.ID, events.NewUserPayload(&newUser))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newUser, nil
+}
+
+// markAccepted claims invitation for the account being created. An invitation
+// accepted by a concurrent request in the meantime is invalid.
+func markAccepted(tx repository.Store, invitation *entity.Invitations, at time.Time) error {
+	err := tx.Invitations().MarkAccepted(invitation, at)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvitationInvalid
+	}
+	return err
+}
+
+func generateInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"testing"
+	"time"
+)
+
+func newRegistrationService(t *testing.T, registration config.RegistrationConfig) (*services.AuthService, repository.Store) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Registration = registration
+	store := repository.NewStore(testutil.OpenDB(t))
+	return services.NewAuthService(store, utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil), store
+}
+
+// invite stores an invitation for email whose token is token.
+func invite(t *testing.T, store repository.Store, email, token string, expiresAt time.Time) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(token))
+	invitation := &entity.Invitations{Email: email, TokenHash: hex.EncodeToString(sum[:]), Role: "admin", ExpiresAt: expiresAt}
+	if err := store.Invitations().Create(invitation); err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+}
+
+func acceptRequest(token string) *request.AcceptInvitationRequest {
+	return &request.AcceptInvitationRequest{Token: token, FirstName: "Jane", LastName: "Doe", Password: "secret123"}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	auth, store := newRegistrationService(t, config.RegistrationConfig{Mode: config.RegistrationInviteOnly})
+	invite(t, store, "jane@example.com", "token", time.Now().Add(time.Hour))
+
+	user, err := auth.AcceptInvitation(context.Background(), acceptRequest("token"))
+	if err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+	if user.Email != "jane@example.com" || user.Role != "admin" {
+		t.Errorf("user = %s %s, want the invited email and role", user.Email, user.Role)
+	}
+	if _, err := store.Invitations().FindPending("jane@example.com", time.Now()); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("invitation is still pending: %v", err)
+	}
+}
+
+func TestAcceptInvitationRejectsInvalidTokens(t *testing.T) {
+	auth, store := newRegistrationService(t, config.RegistrationConfig{Mode: config.RegistrationOpen})
+	invite(t, store, "jane@example.com", "token", time.Now().Add(time.Hour))
+	invite(t, store, "john@example.com", "expired", time.Now().Add(-time.Minute))
+
+	if _, err := auth.AcceptInvitation(context.Background(), acceptRequest("token")); err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+
+	for name, token := range map[string]string{
+		"reused":  "token",
+		"expired": "expired",
+		"unknown": "unknown",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := auth.AcceptInvitation(context.Background(), acceptRequest(token)); !errors.Is(err, services.ErrInvitationInvalid) {
+				t.Fatalf("AcceptInvitation error = %v, want ErrInvitationInvalid", err)
+			}
+		})
+	}
+
+	users, err := store.Users().List()
+	if err != nil || len(users) != 1 {
+		t.Errorf("users = %d, %v; want the single invited account", len(users), err)
+	}
+}
+
+func TestRegistrationModes(t *testing.T) {
+	tests := []struct {
+		name         string
+		registration config.RegistrationConfig
+		register     error
+		accept       error
+	}{
+		{"open", config.RegistrationConfig{Mode: config.RegistrationOpen}, nil, nil},
+		{"invite only", config.RegistrationConfig{Mode: config.RegistrationInviteOnly}, services.ErrInvitationRequired, nil},
+		{"allowed domain", config.RegistrationConfig{Mode: config.RegistrationDomain, AllowedDomains: []string{"example.com"}}, nil, nil},
+		{"other domain", config.RegistrationConfig{Mode: config.RegistrationDomain, AllowedDomains: []string{"example.org"}}, services.ErrEmailDomainNotAllowed, nil},
+		{"closed", config.RegistrationConfig{Mode: config.RegistrationClosed}, services.ErrRegistrationClosed, services.ErrRegistrationClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, store := newRegistrationService(t, tt.registration)
+
+			_, err := auth.HashAndStoreUser(context.Background(), &request.RegisterRequest{
+				FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Password: "secret123",
+			})
+			if !errors.Is(err, tt.register) {
+				t.Errorf("HashAndStoreUser error = %v, want %v", err, tt.register)
+			}
+
+			invite(t, store, "invited@example.net", "token", time.Now().Add(time.Hour))
+			if _, err := auth.AcceptInvitation(context.Background(), acceptRequest("token")); !errors.Is(err, tt.accept) {
+				t.Errorf("AcceptInvitation error = %v, want %v", err, tt.accept)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"log/slog"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyService stores the RSA signing keys and installs them into the JWT
+// issuer.
+type KeyService struct {
+	store     repository.Store
+	jwt       *utils.JWT
+	algorithm string
+	logger    *slog.Logger
+}
+
+func NewKeyService(store repository.Store, jwt *utils.JWT, algorithm string, logger *slog.Logger) *KeyService {
+	return &KeyService{store: store, jwt: jwt, algorithm: algorithm, logger: logger}
+}
+
+// LoadSigningKeys installs the stored RSA keys into the JWT issuer. In RS256
+// mode a key is generated when none is active yet. Rotated keys stay valid
+// for verification until every token they signed has expired.
+func (s *KeyService) LoadSigningKeys(ctx context.Context) error {
+	if s.algorithm != "RS256" {
+		s.jwt.SetSigningKeys(nil, nil)
+		return nil
+	}
+
+	installed, err := s.installSigningKeys(ctx)
+	if err != nil || installed {
+		return err
+	}
+
+	// Instances starting together all find no active key. The unique active
+	// slot lets a single one store its key; the others load that one.
+	if _, err := s.RotateSigningKey(ctx); err != nil {
+		installed, loadErr := s.installSigningKeys(ctx)
+		if loadErr != nil || !installed {
+			return err
+		}
+	}
+	return nil
+}
+
+// installSigningKeys installs the stored keys and reports whether one of
+// them is active.
+func (s *KeyService) installSigningKeys(ctx context.Context) (bool, error) {
+	keys, err := s.store.WithContext(ctx).SigningKeys().ListUsable(time.Now().Add(-TokenTTL))
+	if err != nil {
+		return false, err
+	}
+
+	var signing *utils.SigningKey
+	verification := make(map[string]*rsa.PublicKey, len(keys))
+	for _, key := range keys {
+		privateKey, err := utils.DecodePrivateKey(key.PrivateKey)
+		if err != nil {
+			return false, err
+		}
+		verification[key.KID] = &privateKey.PublicKey
+		if key.Active && signing == nil {
+			signing = &utils.SigningKey{KID: key.KID, PrivateKey: privateKey}
+		}
+	}
+	if signing == nil {
+		return false, nil
+	}
+
+	s.jwt.SetSigningKeys(signing, verification)
+	return true, nil
+}
+
+// RotateSigningKey generates a new active signing key and demotes the
+// previous ones to verification only. It fails when another rotation
+// commits first.
+func (s *KeyService) RotateSigningKey(ctx context.Context) (*entity.SigningKeys, error) {
+	privateKey, err := utils.GenerateRSAKey()
+	if err != nil {
+		return nil, err
+	}
+
+	activeSlot := 1
+	key := entity.SigningKeys{
+		KID:        uuid.NewString(),
+		Algorithm:  "RS256",
+		PrivateKey: utils.EncodePrivateKey(privateKey),
+		Active:     true,
+		ActiveSlot: &activeSlot,
+	}
+
+	err = s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		if err := tx.SigningKeys().Deactivate(time.Now()); err != nil {
+			return err
+		}
+		return tx.SigningKeys().Create(&key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.installSigningKeys(ctx); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RefreshSigningKeys reloads the keys every interval, so that a rotation
+// done by another instance is picked up, until ctx is done.
+func (s *KeyService) RefreshSigningKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.LoadSigningKeys(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "failed to refresh signing keys", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"context"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"testing"
+)
+
+func TestSigningKeyBootstrapKeepsOneActiveKey(t *testing.T) {
+	db := testutil.OpenDB(t)
+	cfg := config.Default().JWT
+	cfg.SigningAlgorithm = "RS256"
+	newKeys := func() (*services.KeyService, *utils.JWT) {
+		jwt := utils.NewJWT(cfg)
+		return services.NewKeyService(repository.NewStore(db), jwt, cfg.SigningAlgorithm, logging.Discard()), jwt
+	}
+
+	first, firstJWT := newKeys()
+	if err := first.LoadSigningKeys(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	second, secondJWT := newKeys()
+	if err := second.LoadSigningKeys(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !firstJWT.HasSigningKey() || !secondJWT.HasSigningKey() {
+		t.Fatal("signing key not installed")
+	}
+
+	var active int64
+	db.Model(&entity.SigningKeys{}).Where("active = ?", true).Count(&active)
+	if active != 1 {
+		t.Fatalf("%d active keys after two startups, want 1", active)
+	}
+
+	// An instance that raced past the lookup cannot store a second one.
+	slot := 1
+	racing := entity.SigningKeys{KID: "racing", Algorithm: "RS256", Active: true, ActiveSlot: &slot}
+	if err := db.Create(&racing).Error; err == nil {
+		t.Error("second active key stored")
+	}
+
+	if _, err := second.RotateSigningKey(context.Background()); err != nil {
+		t.Fatalf("RotateSigningKey: %v", err)
+	}
+	db.Model(&entity.SigningKeys{}).Where("active = ?", true).Count(&active)
+	if active != 1 {
+		t.Errorf("%d active keys after a rotation, want 1", active)
+	}
+}
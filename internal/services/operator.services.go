@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/models/request"
+	"micro/internal/repository"
+	"micro/internal/validation"
+	"strings"
+	"time"
+)
+
+// The methods in this file back the operator CLI. They bypass the
+// registration policy, which only applies to self-service sign up.
+
+func ValidateResetPassword(resetRequest *request.ResetPasswordRequest) error {
+	return validation.Struct(resetRequest)
+}
+
+func ValidateUserRecord(record *request.UserRecord) error {
+	return validation.Struct(record)
+}
+
+// CreateAdmin creates a verified admin account.
+func (s *AuthService) CreateAdmin(ctx context.Context, registerRequest *request.RegisterRequest) (*entity.Users, error) {
+	store := s.store.WithContext(ctx)
+
+	if _, err := store.Users().FindByEmail(registerRequest.Email); err == nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, registerRequest.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser := entity.Users{
+		Name:      fmt.Sprintf("%s %s", registerRequest.FirstName, registerRequest.LastName),
+		FirstName: registerRequest.FirstName,
+		LastName:  registerRequest.LastName,
+		Email:     registerRequest.Email,
+		Password:  hashedPassword,
+		Role:      "admin",
+		Verify:    true,
+	}
+
+	err = store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Create(&newUser); err != nil {
+			return err
+		}
+		return tx.Emit(events.UserRegistered, newUser.ID, events.NewUserPayload(&newUser))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &newUser, nil
+}
+
+// ResetPassword sets a new password and revokes every session of the user,
+// so that whoever knew the old one is signed out.
+func (s *AuthService) ResetPassword(ctx context.Context, resetRequest *request.ResetPasswordRequest) (*entity.Users, error) {
+	store := s.store.WithContext(ctx)
+
+	user, err := store.Users().FindByEmail(resetRequest.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, resetRequest.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = hashedPassword
+
+	err = store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Save(user); err != nil {
+			return err
+		}
+		return tx.Sessions().RevokeAll(user.ID, s.now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ExportUsers returns every account that is not deleted, password hashes
+// included.
+func (s *AuthService) ExportUsers(ctx context.Context) ([]request.UserRecord, error) {
+	users, err := s.store.WithContext(ctx).Users().List()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]request.UserRecord, 0, len(users))
+	for _, user := range users {
+		record := request.UserRecord{
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			Email:        user.Email,
+			PasswordHash: user.Password,
+			Role:         user.Role,
+			Verify:       user.Verify,
+			CreatedAt:    user.CreatedAt.Format(time.RFC3339),
+		}
+		if user.Provider != nil {
+			record.Provider = *user.Provider
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ImportUsers creates an account for every record whose email is not taken
+// yet, in a single transaction, and reports how many were created and
+// skipped. Records must have been checked with ValidateUserRecord.
+func (s *AuthService) ImportUsers(ctx context.Context, records []request.UserRecord) (created, skipped int, err error) {
+	err = s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		created, skipped = 0, 0
+		for _, record := range records {
+			if _, err := tx.Users().FindByEmail(record.Email); err == nil {
+				skipped++
+				continue
+			}
+
+			user := entity.Users{
+				Name:      strings.TrimSpace(record.FirstName + " " + record.LastName),
+				FirstName: record.FirstName,
+				LastName:  record.LastName,
+				Email:     record.Email,
+				Password:  record.PasswordHash,
+				Role:      record.Role,
+				Verify:    record.Verify,
+			}
+			if record.Provider != "" {
+				provider := record.Provider
+				user.Provider = &provider
+			}
+			if createdAt, err := time.Parse(time.RFC3339, record.CreatedAt); err == nil {
+				user.CreatedAt = createdAt
+			}
+
+			if err := tx.Users().Create(&user); err != nil {
+				return fmt.Errorf("%s: %w", record.Email, err)
+			}
+			if err := tx.Emit(events.UserRegistered, user.ID, events.NewUserPayload(&user)); err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	return created, skipped, err
+}
@@ -0,0 +1,85 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/models/request"
+	"micro/internal/repository"
+	"micro/internal/services"
+	"micro/internal/testutil"
+	"micro/internal/utils"
+	"testing"
+)
+
+func newAuthService(t *testing.T) *services.AuthService {
+	t.Helper()
+	cfg := config.Default()
+	store := repository.NewStore(testutil.OpenDB(t))
+	return services.NewAuthService(store, utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil)
+}
+
+func TestCreateAdminIgnoresRegistrationMode(t *testing.T) {
+	cfg := config.Default()
+	cfg.Registration.Mode = config.RegistrationClosed
+	auth := services.NewAuthService(repository.NewStore(testutil.OpenDB(t)), utils.NewJWT(cfg.JWT), cfg, logging.Discard(), nil)
+
+	admin := &request.RegisterRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "secret123"}
+	user, err := auth.CreateAdmin(context.Background(), admin)
+	if err != nil {
+		t.Fatalf("CreateAdmin: %v", err)
+	}
+	if user.Role != "admin" || !user.Verify {
+		t.Errorf("user = %+v, want a verified admin", user)
+	}
+	if _, err := auth.CreateAdmin(context.Background(), admin); !errors.Is(err, services.ErrUserAlreadyExists) {
+		t.Errorf("second CreateAdmin error = %v, want ErrUserAlreadyExists", err)
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	auth := newAuthService(t)
+	user, err := auth.CreateAdmin(context.Background(), &request.RegisterRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "secret123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.CreateSession(context.Background(), user.ID, "test", "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.ResetPassword(context.Background(), &request.ResetPasswordRequest{Email: "ada@example.com", Password: "another123"}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if _, err := auth.AuthenticateUser(context.Background(), "ada@example.com", "another123"); err != nil {
+		t.Errorf("new password rejected: %v", err)
+	}
+	if sessions, _ := auth.ListUserSessions(context.Background(), user.ID); len(sessions) != 0 {
+		t.Errorf("%d sessions still active after reset", len(sessions))
+	}
+}
+
+func TestExportImportUsers(t *testing.T) {
+	source := newAuthService(t)
+	if _, err := source.CreateAdmin(context.Background(), &request.RegisterRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "secret123"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := source.ExportUsers(context.Background())
+	if err != nil || len(records) != 1 {
+		t.Fatalf("ExportUsers = %v, %v", records, err)
+	}
+
+	target := newAuthService(t)
+	created, skipped, err := target.ImportUsers(context.Background(), records)
+	if err != nil || created != 1 || skipped != 0 {
+		t.Fatalf("ImportUsers = %d, %d, %v; want 1 created", created, skipped, err)
+	}
+	if _, err := target.AuthenticateUser(context.Background(), "ada@example.com", "secret123"); err != nil {
+		t.Errorf("imported password hash rejected: %v", err)
+	}
+
+	created, skipped, err = target.ImportUsers(context.Background(), records)
+	if err != nil || created != 0 || skipped != 1 {
+		t.Fatalf("second ImportUsers = %d, %d, %v; want 1 skipped", created, skipped, err)
+	}
+}
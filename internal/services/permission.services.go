@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"strings"
+)
+
+// rolePermissions grants permissions per role. "*" grants everything and
+// "resource:*" every action on a resource.
+var rolePermissions = map[string][]string{
+	"admin":  {"*"},
+	"member": {"profile:read", "profile:write", "sessions:read", "sessions:write"},
+}
+
+// HasPermission reports whether role grants permission.
+func HasPermission(role, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range rolePermissions[role] {
+		if granted == "*" || granted == permission || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPermission reports whether the user's role grants permission and
+// returns that role.
+func (s *AuthService) CheckPermission(ctx context.Context, userID uint, permission string) (bool, string, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, "", err
+	}
+	return HasPermission(user.Role, permission), user.Role, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"micro/internal/models/entity"
+	"micro/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const TokenTTL = time.Hour * 24 * 7
+
+// IssueToken records a new session for the device described by userAgent and
+// ip and returns a JWT bound to it through the jti claim.
+func (s *AuthService) IssueToken(ctx context.Context, user *entity.Users, userAgent, ip string) (token string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IssueToken")
+	defer func() { tracing.End(span, err) }()
+
+	session, err := s.CreateSession(ctx, user.ID, userAgent, ip)
+	if err != nil {
+		return "", err
+	}
+	token, err = s.GenerateJWTToken(user, session)
+	if err != nil {
+		return "", err
+	}
+	s.metrics.TokenIssued()
+	return token, nil
+}
+
+func (s *AuthService) CreateSession(ctx context.Context, userID uint, userAgent, ip string) (*entity.Sessions, error) {
+	now := s.now()
+	session := entity.Sessions{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(TokenTTL),
+	}
+
+	if err := s.store.WithContext(ctx).Sessions().Create(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListUserSessions returns the sessions of a user that are neither revoked
+// nor expired, most recently used first.
+func (s *AuthService) ListUserSessions(ctx context.Context, userID uint) ([]entity.Sessions, error) {
+	return s.store.WithContext(ctx).Sessions().ListActive(userID, s.now())
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	return s.store.WithContext(ctx).Sessions().Revoke(userID, sessionID, s.now())
+}
+
+func (s *AuthService) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return s.store.WithContext(ctx).Sessions().RevokeAll(userID, s.now())
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"micro/internal/apperr"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// sessionTouchInterval limits how often a session's last-seen time is written.
+const sessionTouchInterval = time.Minute
+
+var (
+	ErrInvalidToken   = apperr.New(apperr.Unauthenticated, "invalid_token", "Invalid or expired token")
+	ErrSessionRevoked = apperr.New(apperr.Unauthenticated, "session_revoked", "Session expired or revoked")
+)
+
+// TokenInfo is what an access token resolves to once validated.
+type TokenInfo struct {
+	User    *entity.Users
+	Session *entity.Sessions
+	Claims  *utils.Claims
+}
+
+// ValidateAccessToken checks the signature and expiry of token and that its
+// user and session still exist and are active. It backs middleware.Auth, the
+// gRPC interceptors and introspection.
+func (s *AuthService) ValidateAccessToken(ctx context.Context, token string) (*TokenInfo, error) {
+	store := s.store.WithContext(ctx)
+
+	claims, err := s.jwt.DecodeToken(token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			s.metrics.TokenRejected("expired")
+		} else {
+			s.metrics.TokenRejected("invalid")
+		}
+		return nil, ErrInvalidToken
+	}
+
+	user, err := store.Users().FindByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.metrics.TokenRejected("unknown_user")
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	session, err := store.Sessions().FindForUser(claims.SessionID(), user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.metrics.TokenRejected("unknown_session")
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	info := &TokenInfo{User: user, Session: session, Claims: claims}
+	if !session.Active() {
+		s.metrics.TokenRejected("revoked")
+		return info, ErrSessionRevoked
+	}
+	return info, nil
+}
+
+// TouchSession records that the session was just used, at most once per
+// sessionTouchInterval.
+func (s *AuthService) TouchSession(ctx context.Context, session *entity.Sessions) error {
+	now := s.now()
+	if now.Sub(session.LastSeenAt) <= sessionTouchInterval {
+		return nil
+	}
+	return s.store.WithContext(ctx).Sessions().Touch(session.ID, now)
+}
+
+func (s *AuthService) GetUsersByIDs(ctx context.Context, ids []uint) ([]entity.Users, error) {
+	return s.store.WithContext(ctx).Users().FindByIDs(ids)
+}
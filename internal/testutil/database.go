@@ -0,0 +1,40 @@
+// Package testutil holds helpers shared by the test suites.
+package testutil
+
+import (
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/migrations"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// OpenDB returns a migrated, empty SQLite in-memory database that is closed
+// when the test ends, so the suites need no database server. Queries are
+// bounded by the default query timeout, as in production.
+func OpenDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := config.Connect(config.DatabaseConfig{
+		Driver:       config.DriverSQLite,
+		DSN:          ":memory:",
+		QueryTimeout: config.Default().Database.QueryTimeout,
+	}, logging.Discard())
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	migrator, err := migrations.New(db, config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin creates a span for every query run with a context, e.g. through
+// db.WithContext(ctx). Statements are recorded without their bound values.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	system := db.Dialector.Name()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", beforeQuery("create", system)),
+		cb.Create().After("gorm:create").Register("tracing:after_create", afterQuery),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", beforeQuery("query", system)),
+		cb.Query().After("gorm:query").Register("tracing:after_query", afterQuery),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", beforeQuery("update", system)),
+		cb.Update().After("gorm:update").Register("tracing:after_update", afterQuery),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeQuery("delete", system)),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterQuery),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", beforeQuery("row", system)),
+		cb.Row().After("gorm:row").Register("tracing:after_row", afterQuery),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeQuery("raw", system)),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterQuery),
+	)
+}
+
+func beforeQuery(operation, system string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String(string(semconv.DBSystemKey), system),
+				semconv.DBOperationName(operation),
+			))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
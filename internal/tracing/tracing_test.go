@@ -0,0 +1,67 @@
+package tracing_test
+
+import (
+	"micro/config"
+	"micro/internal/logging"
+	"micro/internal/middleware"
+	"micro/internal/models/entity"
+	"micro/internal/testutil"
+	"micro/internal/tracing"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	db := testutil.OpenDB(t)
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := middleware.New(nil, config.CookieConfig{}, logging.Discard())
+	app := fiber.New()
+	app.Use(mw.RequestID, mw.Trace)
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		var user entity.Users
+		db.WithContext(c.UserContext()).Where("id = ?", c.Params("id")).Find(&user)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := app.Test(req, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want the query and the request", len(spans))
+	}
+	query, request := spans[0], spans[1]
+
+	if request.Name() != "GET /users/:id" {
+		t.Errorf("request span name = %q, want the route template", request.Name())
+	}
+	if got := request.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("request trace = %s, want the one of the traceparent header", got)
+	}
+	if query.Name() != "gorm.query" || query.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("query span %q is not a child of the request span", query.Name())
+	}
+	for _, attr := range query.Attributes() {
+		if attr.Key == "db.query.text" && strings.Contains(attr.Value.AsString(), "42") {
+			t.Errorf("query span statement %q includes the bound values", attr.Value.AsString())
+		}
+	}
+}
@@ -1,17 +1,80 @@
 package utils
 
 import (
+	"crypto/rsa"
+	"errors"
 	"fmt"
-	"os"
+	"micro/config"
+	"strconv"
+	"sync"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-var SecretKey string
+// Claims are the claims of the access tokens issued by this service. The
+// embedded ID is the jti, which is the id of the session the token belongs to.
+type Claims struct {
+	UserID uint   `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// SessionID returns the session the token was issued for.
+func (c *Claims) SessionID() string {
+	return c.ID
+}
+
+// JWT issues and verifies access tokens with the configured algorithm: with
+// the HS256 secret, or in RS256 mode with the current RSA signing key. Only
+// tokens of that algorithm are accepted, and HS256 ones as well in RS256
+// mode when AcceptHS256 is set.
+type JWT struct {
+	cfg config.JWTConfig
+
+	mu      sync.RWMutex
+	signing *SigningKey
+	public  map[string]*rsa.PublicKey
+}
+
+func NewJWT(cfg config.JWTConfig) *JWT {
+	return &JWT{cfg: cfg, public: map[string]*rsa.PublicKey{}}
+}
+
+// GenerateToken signs claims, filling in missing sub, iss, aud, iat and nbf
+// claims.
+func (j *JWT) GenerateToken(claims *Claims) (string, error) {
+	now := jwt.NewNumericDate(time.Now())
+	if claims.Subject == "" {
+		claims.Subject = strconv.FormatUint(uint64(claims.UserID), 10)
+	}
+	if claims.Issuer == "" {
+		claims.Issuer = j.cfg.Issuer
+	}
+	if len(claims.Audience) == 0 {
+		claims.Audience = jwt.ClaimStrings{j.cfg.Audience}
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = now
+	}
+	if claims.NotBefore == nil {
+		claims.NotBefore = now
+	}
+
+	if j.cfg.SigningAlgorithm == jwt.SigningMethodRS256.Alg() {
+		key := j.currentSigningKey()
+		if key == nil {
+			return "", errors.New("no active signing key")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = key.KID
+		return token.SignedString(key.PrivateKey)
+	}
 
-func GenerateToken(claims *jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	webtoken, err := token.SignedString([]byte(SecretKey))
+	webtoken, err := token.SignedString([]byte(j.cfg.Secret))
 	if err != nil {
 		return "", err
 	}
@@ -19,37 +82,58 @@ func GenerateToken(claims *jwt.MapClaims) (string, error) {
 	return webtoken, nil
 }
 
-func VerifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, isValid := token.Method.(*jwt.SigningMethodHMAC); !isValid {
+// VerifyToken checks the signature of tokenString and validates exp, nbf,
+// iat, iss and aud, allowing the configured clock skew.
+func (j *JWT) VerifyToken(tokenString string) (*jwt.Token, *Claims, error) {
+	claims := new(Claims)
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method {
+		case jwt.SigningMethodHS256:
+			return []byte(j.cfg.Secret), nil
+		case jwt.SigningMethodRS256:
+			kid, _ := token.Header["kid"].(string)
+			if key, ok := j.publicKey(kid); ok {
+				return key, nil
+			}
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(SecretKey), nil
-	})
+	},
+		jwt.WithValidMethods(j.validMethods()),
+		jwt.WithIssuer(j.cfg.Issuer),
+		jwt.WithAudience(j.cfg.Audience),
+		jwt.WithLeeway(j.cfg.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return token, nil
+	return token, claims, nil
+}
+
+// validMethods returns the algorithms VerifyToken accepts.
+func (j *JWT) validMethods() []string {
+	if j.cfg.SigningAlgorithm != jwt.SigningMethodRS256.Alg() {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	if j.cfg.AcceptHS256 {
+		return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodHS256.Alg()}
+	}
+	return []string{jwt.SigningMethodRS256.Alg()}
 }
 
-func DecodeToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := VerifyToken(tokenString)
+func (j *JWT) DecodeToken(tokenString string) (*Claims, error) {
+	token, claims, err := j.VerifyToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	claims, isOke := token.Claims.(jwt.MapClaims)
-	if isOke && token.Valid {
-		return claims, nil
+	if !token.Valid || claims.NotBefore == nil || claims.UserID == 0 || claims.ID == "" {
+		return nil, fmt.Errorf("Invalid Token")
 	}
 
-	return nil, fmt.Errorf("Invalid Token")
-}
-
-func init() {
-	SecretKey = os.Getenv("SECRET_KEY")
-	if SecretKey == "" {
-		SecretKey = "n3^|e{jJ,|UmsT(ch42^yl8x^=7#zp}q"
-	}
+	return claims, nil
 }
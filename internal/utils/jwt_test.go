@@ -0,0 +1,155 @@
+package utils_test
+
+import (
+	"crypto/rsa"
+	"micro/config"
+	"micro/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func claims() *utils.Claims {
+	return &utils.Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestRS256RejectsSecretSignedTokens(t *testing.T) {
+	hs256 := utils.NewJWT(config.Default().JWT)
+	forged, err := hs256.GenerateToken(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default().JWT
+	cfg.SigningAlgorithm = "RS256"
+	rs256 := utils.NewJWT(cfg)
+	if _, err := rs256.GenerateToken(claims()); err == nil {
+		t.Error("RS256 token issued without a signing key")
+	}
+
+	key, err := utils.GenerateRSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256.SetSigningKeys(&utils.SigningKey{KID: "k1", PrivateKey: key}, nil)
+	token, err := rs256.GenerateToken(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs256.DecodeToken(token); err != nil {
+		t.Errorf("RS256 token rejected: %v", err)
+	}
+	if _, err := rs256.DecodeToken(forged); err == nil {
+		t.Error("HS256 token accepted in RS256 mode")
+	}
+
+	cfg.AcceptHS256 = true
+	transition := utils.NewJWT(cfg)
+	if _, err := transition.DecodeToken(forged); err != nil {
+		t.Errorf("HS256 token rejected with AcceptHS256: %v", err)
+	}
+}
+
+func TestHS256RejectsRSASignedTokens(t *testing.T) {
+	cfg := config.Default().JWT
+	cfg.SigningAlgorithm = "RS256"
+	rs256 := utils.NewJWT(cfg)
+	key, err := utils.GenerateRSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256.SetSigningKeys(&utils.SigningKey{KID: "k1", PrivateKey: key}, nil)
+	token, err := rs256.GenerateToken(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs256 := utils.NewJWT(config.Default().JWT)
+	hs256.SetSigningKeys(nil, map[string]*rsa.PublicKey{"k1": &key.PublicKey})
+	if _, err := hs256.DecodeToken(token); err == nil {
+		t.Error("RS256 token accepted in HS256 mode")
+	}
+}
+
+func TestDecodeTokenValidatesClaims(t *testing.T) {
+	cfg := config.Default().JWT
+	cfg.ClockSkew = 30 * time.Second
+	j := utils.NewJWT(cfg)
+	now := time.Now()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"id":  1,
+			"jti": "session",
+			"sub": "1",
+			"iss": cfg.Issuer,
+			"aud": cfg.Audience,
+			"iat": now.Unix(),
+			"nbf": now.Unix(),
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c jwt.MapClaims)
+		valid  bool
+	}{
+		{"valid", func(c jwt.MapClaims) {}, true},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "someone-else" }, false},
+		{"missing issuer", func(c jwt.MapClaims) { delete(c, "iss") }, false},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "other" }, false},
+		{"audience among several", func(c jwt.MapClaims) { c["aud"] = []string{"other", cfg.Audience} }, true},
+		{"missing audience", func(c jwt.MapClaims) { delete(c, "aud") }, false},
+		{"not yet valid within skew", func(c jwt.MapClaims) { c["nbf"] = now.Add(20 * time.Second).Unix() }, true},
+		{"not yet valid", func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() }, false},
+		{"missing nbf", func(c jwt.MapClaims) { delete(c, "nbf") }, false},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = now.Add(time.Minute).Unix() }, false},
+		{"expired within skew", func(c jwt.MapClaims) { c["exp"] = now.Add(-20 * time.Second).Unix() }, true},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() }, false},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }, false},
+		{"missing user id", func(c jwt.MapClaims) { delete(c, "id") }, false},
+		{"zero user id", func(c jwt.MapClaims) { c["id"] = 0 }, false},
+		{"negative user id", func(c jwt.MapClaims) { c["id"] = -1 }, false},
+		{"fractional user id", func(c jwt.MapClaims) { c["id"] = 1.5 }, false},
+		{"string user id", func(c jwt.MapClaims) { c["id"] = "1" }, false},
+		{"object user id", func(c jwt.MapClaims) { c["id"] = map[string]int{"id": 1} }, false},
+		{"missing jti", func(c jwt.MapClaims) { delete(c, "jti") }, false},
+		{"empty jti", func(c jwt.MapClaims) { c["jti"] = "" }, false},
+		{"numeric jti", func(c jwt.MapClaims) { c["jti"] = 7 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(cfg.Secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := j.DecodeToken(token)
+			if tt.valid && (err != nil || decoded.UserID != 1 || decoded.SessionID() != "session") {
+				t.Errorf("DecodeToken = %+v, %v; want the claims", decoded, err)
+			}
+			if !tt.valid && (err == nil || decoded != nil) {
+				t.Errorf("DecodeToken = %+v, %v; want an error", decoded, err)
+			}
+		})
+	}
+}
+
+func TestDecodeTokenRejectsMalformedTokens(t *testing.T) {
+	j := utils.NewJWT(config.Default().JWT)
+	for _, token := range []string{"", "not a token", "a.b.c", "eyJhbGciOiJIUzI1NiJ9.e30.", "eyJhbGciOiJub25lIn0.eyJpZCI6MX0."} {
+		if claims, err := j.DecodeToken(token); err == nil || claims != nil {
+			t.Errorf("DecodeToken(%q) = %+v, %v; want an error", token, claims, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey is an RSA key identified by the kid header of the tokens it signs.
+type SigningKey struct {
+	KID        string
+	PrivateKey *rsa.PrivateKey
+}
+
+// SetSigningKeys installs the key used to sign new tokens and the public keys
+// accepted when verifying them. In RS256 mode no token can be issued while
+// the signing key is nil.
+func (j *JWT) SetSigningKeys(signing *SigningKey, verification map[string]*rsa.PublicKey) {
+	public := make(map[string]*rsa.PublicKey, len(verification))
+	for kid, key := range verification {
+		public[kid] = key
+	}
+	if signing != nil {
+		public[signing.KID] = &signing.PrivateKey.PublicKey
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.signing = signing
+	j.public = public
+}
+
+// PublicKeys returns the RSA keys tokens are currently verified with, by kid.
+func (j *JWT) PublicKeys() map[string]*rsa.PublicKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	keys := make(map[string]*rsa.PublicKey, len(j.public))
+	for kid, key := range j.public {
+		keys[kid] = key
+	}
+	return keys
+}
+
+// HasSigningKey reports whether an RSA signing key is installed.
+func (j *JWT) HasSigningKey() bool {
+	return j.currentSigningKey() != nil
+}
+
+func (j *JWT) currentSigningKey() *SigningKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.signing
+}
+
+func (j *JWT) publicKey(kid string) (*rsa.PublicKey, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	key, ok := j.public[kid]
+	return key, ok
+}
+
+func GenerateRSAKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+}
+
+func EncodePrivateKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+func DecodePrivateKey(encoded string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM private key")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// PasswordMinLength bounds passwords in characters, and
+	// PasswordMaxLength in bytes since bcrypt ignores whatever follows the
+	// 72nd byte.
+	PasswordMinLength = 8
+	PasswordMaxLength = 72
+
+	// PersonNameMaxLength bounds first and last names, in characters.
+	PersonNameMaxLength = 100
+)
+
+// disposableDomains are throwaway email providers registration refuses.
+var disposableDomains = map[string]bool{
+	"10minutemail.com":  true,
+	"dispostable.com":   true,
+	"fakeinbox.com":     true,
+	"getnada.com":       true,
+	"guerrillamail.com": true,
+	"maildrop.cc":       true,
+	"mailinator.com":    true,
+	"mintemail.com":     true,
+	"sharklasers.com":   true,
+	"temp-mail.org":     true,
+	"tempmail.com":      true,
+	"throwawaymail.com": true,
+	"trashmail.com":     true,
+	"yopmail.com":       true,
+}
+
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}
+
+var rules = []rule{
+	{
+		tag: "password",
+		fn:  validPassword,
+		messages: map[string]string{
+			"en": "{0} must be at least 8 characters and at most 72 bytes long and contain a letter and a digit",
+			"id": "{0} harus terdiri dari minimal 8 karakter dan maksimal 72 byte serta mengandung huruf dan angka",
+		},
+	},
+	{
+		tag: "personname",
+		fn:  validPersonName,
+		messages: map[string]string{
+			"en": "{0} may only contain letters, spaces, apostrophes, hyphens and periods",
+			"id": "{0} hanya boleh berisi huruf, spasi, apostrof, tanda hubung, dan titik",
+		},
+	},
+	{
+		tag: "notdisposable",
+		fn:  notDisposable,
+		messages: map[string]string{
+			"en": "{0} must not use a disposable email provider",
+			"id": "{0} tidak boleh menggunakan penyedia email sekali pakai",
+		},
+	},
+}
+
+// validPassword enforces the password policy: at least PasswordMinLength
+// characters, at most PasswordMaxLength bytes, with at least a letter and a
+// digit.
+func validPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if utf8.RuneCountInString(password) < PasswordMinLength || len(password) > PasswordMaxLength {
+		return false
+	}
+	return strings.IndexFunc(password, unicode.IsLetter) >= 0 && strings.IndexFunc(password, unicode.IsDigit) >= 0
+}
+
+// validPersonName accepts letters of any script, with the spaces,
+// apostrophes, hyphens and periods found in names.
+func validPersonName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > PersonNameMaxLength {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && !strings.ContainsRune(" '’-.", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// notDisposable rejects addresses at a known disposable email provider,
+// subdomains included.
+func notDisposable(fl validator.FieldLevel) bool {
+	_, domain, ok := strings.Cut(strings.ToLower(fl.Field().String()), "@")
+	if !ok {
+		return true
+	}
+	for domain != "" {
+		if disposableDomains[domain] {
+			return false
+		}
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+// Package validation holds the validator shared by every request struct,
+// with the service's own rules, and turns its errors into field-level
+// messages in the client's language.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLanguage is used when the client accepts none of Languages.
+const DefaultLanguage = "en"
+
+// Languages are the languages messages are available in.
+var Languages = []string{"en", "id"}
+
+// FieldError is one invalid field of a request, named as in its JSON.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	validate   = validator.New()
+	translator = ut.New(en.New(), en.New(), id.New())
+)
+
+func init() {
+	validate.RegisterTagNameFunc(jsonName)
+	for _, r := range rules {
+		if err := validate.RegisterValidation(r.tag, r.fn); err != nil {
+			panic(err)
+		}
+	}
+
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"id": idTranslations.RegisterDefaultTranslations,
+	}
+	for _, lang := range Languages {
+		trans, _ := translator.GetTranslator(lang)
+		if err := register[lang](validate, trans); err != nil {
+			panic(err)
+		}
+		for _, r := range rules {
+			if err := registerMessage(trans, r.tag, r.messages[lang]); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// Struct validates s against its validate tags. The error, if any, is a
+// validator.ValidationErrors.
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// Fields returns the invalid fields of err, with messages in the language
+// preferred by acceptLanguage, an Accept-Language header. It returns nil
+// when err is not a validation error.
+func Fields(err error, acceptLanguage string) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	trans, _ := translator.GetTranslator(Language(acceptLanguage))
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: fieldErr.Translate(trans),
+		})
+	}
+	return fields
+}
+
+// Language returns the first of Languages acceptLanguage prefers, matching
+// on the primary subtag so that id-ID selects id, or DefaultLanguage.
+func Language(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag != "" && q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		primary, _, _ := strings.Cut(strings.ToLower(t.tag), "-")
+		for _, lang := range Languages {
+			if primary == lang {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func registerMessage(trans ut.Translator, tag, message string) error {
+	return validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, message, true)
+		},
+		func(ut ut.Translator, fieldErr validator.FieldError) string {
+			text, err := ut.T(tag, fieldErr.Field())
+			if err != nil {
+				return fieldErr.Error()
+			}
+			return text
+		})
+}
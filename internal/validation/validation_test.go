@@ -0,0 +1,74 @@
+package validation_test
+
+import (
+	"micro/internal/validation"
+	"strings"
+	"testing"
+)
+
+type signup struct {
+	Name     string `json:"name" validate:"required,personname"`
+	Email    string `json:"email" validate:"required,email,notdisposable"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+func TestRules(t *testing.T) {
+	valid := signup{Name: "Siti Nur'aini-Putri", Email: "siti@example.com", Password: "secret123"}
+	if err := validation.Struct(valid); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*signup)
+		field  string
+	}{
+		{"password without digit", func(s *signup) { s.Password = "secretsecret" }, "password"},
+		{"short password", func(s *signup) { s.Password = "abc123" }, "password"},
+		{"short multibyte password", func(s *signup) { s.Password = "ééé1" }, "password"},
+		{"password over 72 bytes", func(s *signup) { s.Password = strings.Repeat("é", 36) + "1" }, "password"},
+		{"name with digits", func(s *signup) { s.Name = "R2-D2" }, "name"},
+		{"blank name", func(s *signup) { s.Name = "   " }, "name"},
+		{"disposable email", func(s *signup) { s.Email = "siti@mailinator.com" }, "email"},
+		{"disposable subdomain", func(s *signup) { s.Email = "siti@inbox.yopmail.com" }, "email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := valid
+			tt.modify(&request)
+			fields := validation.Fields(validation.Struct(request), "")
+			if len(fields) != 1 || fields[0].Field != tt.field {
+				t.Errorf("fields = %+v, want %s only", fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestFieldsAreTranslated(t *testing.T) {
+	err := validation.Struct(signup{Name: "Siti", Email: "siti@example.com"})
+
+	english := validation.Fields(err, "")
+	if len(english) != 1 || english[0].Rule != "required" || english[0].Message != "password is a required field" {
+		t.Errorf("english = %+v", english)
+	}
+	indonesian := validation.Fields(err, "id")
+	if len(indonesian) != 1 || indonesian[0].Message != "password wajib diisi" {
+		t.Errorf("indonesian = %+v", indonesian)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                        "en",
+		"id-ID,en;q=0.5":          "id",
+		"en-US,id;q=0.9":          "en",
+		"fr-FR,id;q=0.8,en;q=0.7": "id",
+		"id;q=0,en":               "en",
+		"de":                      "en",
+	}
+	for header, want := range tests {
+		if got := validation.Language(header); got != want {
+			t.Errorf("Language(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	defaultDispatchInterval = 5 * time.Second
+	defaultMaxAttempts      = 8
+	defaultDeliveryLease    = time.Minute
+	baseRetryDelay          = 30 * time.Second
+	maxRetryDelay           = 6 * time.Hour
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// secret. Receivers recompute it and should reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sink is the events.Sink that fans an event out into one pending delivery
+// per subscribed endpoint. The Dispatcher sends them afterwards.
+type Sink struct {
+	store repository.Store
+}
+
+func NewSink(store repository.Store) *Sink {
+	return &Sink{store: store}
+}
+
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+func (s *Sink) Publish(ctx context.Context, event events.Event) error {
+	store := s.store.WithContext(ctx)
+	endpoints, err := store.WebhookEndpoints().List()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []entity.WebhookDeliveries
+	for i := range endpoints {
+		if !endpoints[i].Active || !Subscribed(&endpoints[i], string(event.Type)) {
+			continue
+		}
+		deliveries = append(deliveries, entity.WebhookDeliveries{
+			EndpointID:    endpoints[i].ID,
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			Payload:       string(body),
+			Status:        StatusPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	// The outbox relay may publish the same event again after a partial
+	// failure; Enqueue keeps a single delivery per endpoint and event.
+	return store.WebhookDeliveries().Enqueue(deliveries)
+}
+
+// Dispatcher sends pending deliveries to their endpoints. Dispatchers of
+// several instances can share a database: a delivery is leased to one of
+// them for Lease before it is sent.
+type Dispatcher struct {
+	Store       repository.Store
+	Client      *http.Client
+	Interval    time.Duration
+	MaxAttempts int
+	BatchSize   int
+	Lease       time.Duration
+	Logger      *slog.Logger
+}
+
+func NewDispatcher(store repository.Store, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Logger:      logger,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Interval:    defaultDispatchInterval,
+		MaxAttempts: defaultMaxAttempts,
+		BatchSize:   100,
+		Lease:       defaultDeliveryLease,
+	}
+}
+
+// Run dispatches due deliveries every Interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.DispatchDue(ctx); err != nil {
+				d.Logger.ErrorContext(ctx, "failed to dispatch webhooks", "error", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	deliveries, err := d.Store.WithContext(ctx).WebhookDeliveries().ListDue(time.Now(), d.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Leasing postpones the next attempt, so that other dispatchers
+		// leave the delivery alone while it is sent.
+		now := time.Now()
+		leased, err := d.Store.WithContext(ctx).WebhookDeliveries().Lease(deliveries[i].ID, now, now.Add(d.Lease))
+		if err != nil {
+			return err
+		}
+		if !leased {
+			continue
+		}
+		if err := d.attempt(ctx, &deliveries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt sends one delivery and records the outcome. Deliveries of
+// endpoints deactivated or deleted since they were queued are skipped. It
+// only returns an error when the endpoint could not be read or the outcome
+// could not be saved.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *entity.WebhookDeliveries) error {
+	store := d.Store.WithContext(ctx)
+	endpoint, err := store.WebhookEndpoints().FindByID(delivery.EndpointID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		delivery.Status = StatusSkipped
+		delivery.LastError = "endpoint no longer exists"
+		return store.WebhookDeliveries().Save(delivery)
+	case err != nil:
+		return err
+	case !endpoint.Active:
+		delivery.Status = StatusSkipped
+		delivery.LastError = "endpoint is inactive"
+		return store.WebhookDeliveries().Save(delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(ctx, endpoint, delivery)
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = StatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = StatusFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.NextAttemptAt = time.Now().Add(retryDelay(delivery.Attempts))
+		delivery.LastError = sendErr.Error()
+	}
+
+	return store.WebhookDeliveries().Save(delivery)
+}
+
+// send posts delivery to endpoint, within the lease of the delivery.
+func (d *Dispatcher) send(ctx context.Context, endpoint *entity.WebhookEndpoints, delivery *entity.WebhookDeliveries) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.Lease)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay doubles baseRetryDelay for every failed attempt, up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,300 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"micro/internal/events"
+	"micro/internal/logging"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"micro/internal/testutil"
+	"micro/internal/webhooks"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func createEndpoint(t *testing.T, db *gorm.DB, url string) *entity.WebhookEndpoints {
+	t.Helper()
+	endpoint, err := webhooks.NewService(repository.NewStore(db)).CreateEndpoint(context.Background(), webhooks.EndpointInput{
+		URL: url, Secret: "whsec_test", Events: []string{webhooks.AllEvents}, Active: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+// queue stores a due delivery to endpoint that already used attempts.
+func queue(t *testing.T, db *gorm.DB, endpoint *entity.WebhookEndpoints, attempts int) *entity.WebhookDeliveries {
+	t.Helper()
+	delivery := entity.WebhookDeliveries{
+		EndpointID:    endpoint.ID,
+		EventID:       "evt_" + strconv.Itoa(attempts),
+		EventType:     "user.registered",
+		Payload:       `{"id":"evt_1"}`,
+		Status:        webhooks.StatusPending,
+		Attempts:      attempts,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	if err := db.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &delivery
+}
+
+func reload(t *testing.T, db *gorm.DB, delivery *entity.WebhookDeliveries) *entity.WebhookDeliveries {
+	t.Helper()
+	var reloaded entity.WebhookDeliveries
+	if err := db.First(&reloaded, delivery.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &reloaded
+}
+
+func respond(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+}
+
+func TestSign(t *testing.T) {
+	got := webhooks.Sign("whsec_test", 1700000000, []byte(`{"id":"evt_1"}`))
+	if want := "c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"; got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(server.Close)
+
+	db := testutil.OpenDB(t)
+	delivery := queue(t, db, createEndpoint(t, db, server.URL), 0)
+	if err := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard()).DispatchDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if request == nil {
+		t.Fatal("endpoint received no request")
+	}
+	timestamp, err := strconv.ParseInt(request.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if got, want := request.Header.Get(webhooks.HeaderSignature), "sha256="+webhooks.Sign("whsec_test", timestamp, body); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if request.Header.Get(webhooks.HeaderID) != delivery.EventID || request.Header.Get(webhooks.HeaderEvent) != delivery.EventType || string(body) != delivery.Payload {
+		t.Errorf("request headers = %v, body = %s", request.Header, body)
+	}
+
+	delivered := reload(t, db, delivery)
+	if delivered.Status != webhooks.StatusSucceeded || delivered.Attempts != 1 || delivered.DeliveredAt == nil || delivered.LastStatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v, want succeeded on the first attempt", delivered)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	server := respond(http.StatusInternalServerError)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{20, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempts), func(t *testing.T) {
+			db := testutil.OpenDB(t)
+			delivery := queue(t, db, createEndpoint(t, db, server.URL), tt.attempts)
+			dispatcher := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard())
+			dispatcher.MaxAttempts = 100
+
+			before := time.Now()
+			if err := dispatcher.DispatchDue(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			after := time.Now()
+
+			retried := reload(t, db, delivery)
+			if retried.Status != webhooks.StatusPending || retried.Attempts != tt.attempts+1 || retried.LastStatusCode != http.StatusInternalServerError {
+				t.Errorf("delivery = %+v, want a pending retry", retried)
+			}
+			if retried.NextAttemptAt.Before(before.Add(tt.delay).Truncate(time.Second)) || retried.NextAttemptAt.After(after.Add(tt.delay)) {
+				t.Errorf("next attempt in %v, want %v", retried.NextAttemptAt.Sub(before), tt.delay)
+			}
+		})
+	}
+}
+
+func TestDispatcherFailsAfterMaxAttempts(t *testing.T) {
+	server := respond(http.StatusBadGateway)
+	t.Cleanup(server.Close)
+
+	db := testutil.OpenDB(t)
+	dispatcher := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard())
+	delivery := queue(t, db, createEndpoint(t, db, server.URL), dispatcher.MaxAttempts-1)
+	if err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if failed := reload(t, db, delivery); failed.Status != webhooks.StatusFailed || failed.Attempts != dispatcher.MaxAttempts || failed.LastError == "" {
+		t.Errorf("delivery = %+v, want failed after %d attempts", failed, dispatcher.MaxAttempts)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	delivery := queue(t, db, createEndpoint(t, db, server.URL), 8)
+	if err := db.Model(delivery).Updates(map[string]interface{}{"status": webhooks.StatusFailed, "next_attempt_at": time.Now().Add(time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	redelivered, err := webhooks.NewService(repository.NewStore(db)).Redeliver(ctx, delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivered.Status != webhooks.StatusPending || redelivered.Attempts != 0 || redelivered.NextAttemptAt.After(time.Now()) {
+		t.Errorf("redelivered = %+v, want pending, due now, with a fresh retry budget", redelivered)
+	}
+
+	if err := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard()).DispatchDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if received.Load() != 1 || reload(t, db, delivery).Status != webhooks.StatusSucceeded {
+		t.Errorf("endpoint received %d requests, want the redelivery", received.Load())
+	}
+}
+
+func TestDispatcherSkipsDeliveriesLeasedByAnotherDispatcher(t *testing.T) {
+	db := testutil.OpenDB(t)
+	other := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard())
+
+	// The other dispatcher runs while the endpoint is being called.
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if received.Add(1) == 1 {
+			if err := other.DispatchDue(r.Context()); err != nil {
+				t.Error(err)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	queue(t, db, createEndpoint(t, db, server.URL), 0)
+	if err := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard()).DispatchDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := received.Load(); n != 1 {
+		t.Errorf("endpoint received %d requests, want 1", n)
+	}
+}
+
+func TestDispatcherSkipsInactiveEndpoints(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	endpoint := createEndpoint(t, db, server.URL)
+	delivery := queue(t, db, endpoint, 0)
+
+	// Deactivated after the delivery was queued.
+	if _, err := webhooks.NewService(repository.NewStore(db)).UpdateEndpoint(ctx, endpoint.ID, webhooks.EndpointInput{URL: server.URL, Events: []string{webhooks.AllEvents}, Active: false}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := webhooks.NewDispatcher(repository.NewStore(db), logging.Discard()).DispatchDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := received.Load(); n != 0 {
+		t.Errorf("inactive endpoint received %d requests", n)
+	}
+	if skipped := reload(t, db, delivery); skipped.Status != webhooks.StatusSkipped {
+		t.Errorf("delivery status = %q, want %q", skipped.Status, webhooks.StatusSkipped)
+	}
+}
+
+// sinkStore serves the endpoints and records the deliveries of Sink.Publish.
+// The methods it leaves out are nil.
+type sinkStore struct {
+	repository.Store
+	endpoints []entity.WebhookEndpoints
+	queued    []entity.WebhookDeliveries
+}
+
+func (s *sinkStore) WithContext(ctx context.Context) repository.Store { return s }
+
+func (s *sinkStore) WebhookEndpoints() repository.WebhookEndpointRepository {
+	return sinkEndpoints{store: s}
+}
+
+func (s *sinkStore) WebhookDeliveries() repository.WebhookDeliveryRepository {
+	return sinkDeliveries{store: s}
+}
+
+type sinkEndpoints struct {
+	repository.WebhookEndpointRepository
+	store *sinkStore
+}
+
+func (r sinkEndpoints) List() ([]entity.WebhookEndpoints, error) { return r.store.endpoints, nil }
+
+type sinkDeliveries struct {
+	repository.WebhookDeliveryRepository
+	store *sinkStore
+}
+
+func (r sinkDeliveries) Enqueue(deliveries []entity.WebhookDeliveries) error {
+	r.store.queued = append(r.store.queued, deliveries...)
+	return nil
+}
+
+func TestSinkQueuesSubscribedEndpoints(t *testing.T) {
+	store := &sinkStore{endpoints: []entity.WebhookEndpoints{
+		{ID: 1, Events: webhooks.AllEvents, Active: true},
+		{ID: 2, Events: "user.deleted,user.registered", Active: true},
+		{ID: 3, Events: "user.deleted", Active: true},
+		{ID: 4, Events: webhooks.AllEvents, Active: false},
+	}}
+
+	event := events.Event{ID: "evt_1", Type: events.UserRegistered}
+	if err := webhooks.NewSink(store).Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.queued) != 2 || store.queued[0].EndpointID != 1 || store.queued[1].EndpointID != 2 {
+		t.Fatalf("queued %+v, want deliveries to endpoints 1 and 2", store.queued)
+	}
+	for _, delivery := range store.queued {
+		if delivery.EventID != event.ID || delivery.Status != webhooks.StatusPending || delivery.NextAttemptAt.After(time.Now()) {
+			t.Errorf("delivery = %+v, want the event pending and due", delivery)
+		}
+	}
+}
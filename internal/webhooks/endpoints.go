@@ -0,0 +1,176 @@
+// Package webhooks delivers domain events to HTTP endpoints registered by
+// admins. Each request is signed with the endpoint secret and retried with
+// exponential backoff until it succeeds or runs out of attempts.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"micro/internal/apperr"
+	"micro/internal/events"
+	"micro/internal/models/entity"
+	"micro/internal/repository"
+	"strings"
+	"time"
+)
+
+// AllEvents subscribes an endpoint to every event type.
+const AllEvents = "*"
+
+const (
+	StatusPending   = entity.WebhookDeliveryPending
+	StatusSucceeded = entity.WebhookDeliverySucceeded
+	StatusFailed    = entity.WebhookDeliveryFailed
+	// StatusSkipped deliveries were due once their endpoint was deactivated
+	// or deleted, and were not sent. Redeliver queues them again.
+	StatusSkipped = entity.WebhookDeliverySkipped
+)
+
+var ErrUnknownEventType = apperr.New(apperr.Invalid, "unknown_event_type", "Unknown event type")
+
+// EndpointInput holds the admin supplied fields of an endpoint. An empty
+// Secret on creation generates one.
+type EndpointInput struct {
+	URL         string
+	Secret      string
+	Events      []string
+	Description string
+	Active      bool
+}
+
+// Service manages the registered endpoints and their delivery log.
+type Service struct {
+	store repository.Store
+}
+
+func NewService(store repository.Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) CreateEndpoint(ctx context.Context, input EndpointInput) (*entity.WebhookEndpoints, error) {
+	if err := validateEvents(input.Events); err != nil {
+		return nil, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	endpoint := entity.WebhookEndpoints{
+		URL:         input.URL,
+		Secret:      secret,
+		Events:      strings.Join(input.Events, ","),
+		Description: input.Description,
+		Active:      input.Active,
+	}
+	if err := s.store.WithContext(ctx).WebhookEndpoints().Create(&endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (s *Service) ListEndpoints(ctx context.Context) ([]entity.WebhookEndpoints, error) {
+	return s.store.WithContext(ctx).WebhookEndpoints().List()
+}
+
+func (s *Service) GetEndpoint(ctx context.Context, id uint) (*entity.WebhookEndpoints, error) {
+	return s.store.WithContext(ctx).WebhookEndpoints().FindByID(id)
+}
+
+// UpdateEndpoint replaces the endpoint settings. The secret is only changed
+// when input.Secret is set.
+func (s *Service) UpdateEndpoint(ctx context.Context, id uint, input EndpointInput) (*entity.WebhookEndpoints, error) {
+	if err := validateEvents(input.Events); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.URL = input.URL
+	endpoint.Events = strings.Join(input.Events, ",")
+	endpoint.Description = input.Description
+	endpoint.Active = input.Active
+	if input.Secret != "" {
+		endpoint.Secret = input.Secret
+	}
+
+	if err := s.store.WithContext(ctx).WebhookEndpoints().Save(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *Service) DeleteEndpoint(ctx context.Context, id uint) error {
+	return s.store.WithContext(ctx).WebhookEndpoints().Delete(id)
+}
+
+// ListDeliveries returns one page of the delivery log of an endpoint, newest
+// first, and the total number of deliveries.
+func (s *Service) ListDeliveries(ctx context.Context, endpointID uint, page, perPage int) ([]entity.WebhookDeliveries, int64, error) {
+	return s.store.WithContext(ctx).WebhookDeliveries().ListByEndpoint(endpointID, (page-1)*perPage, perPage)
+}
+
+// Redeliver schedules a delivery to be sent again right away with a fresh
+// retry budget, whatever its current status.
+func (s *Service) Redeliver(ctx context.Context, deliveryID uint) (*entity.WebhookDeliveries, error) {
+	deliveries := s.store.WithContext(ctx).WebhookDeliveries()
+	delivery, err := deliveries.FindByID(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := deliveries.Save(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Subscribed reports whether endpoint wants events of eventType.
+func Subscribed(endpoint *entity.WebhookEndpoints, eventType string) bool {
+	for _, subscribed := range strings.Split(endpoint.Events, ",") {
+		if subscribed == AllEvents || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func validateEvents(subscriptions []string) error {
+	for _, subscription := range subscriptions {
+		if subscription == AllEvents {
+			continue
+		}
+		known := false
+		for _, eventType := range events.Types() {
+			if subscription == string(eventType) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return apperr.New(apperr.Invalid, ErrUnknownEventType.Code, fmt.Sprintf("Unknown event type %q", subscription))
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
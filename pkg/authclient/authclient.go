@@ -0,0 +1,218 @@
+// Package authclient lets downstream services authenticate requests carrying
+// tokens issued by the auth service, either by verifying them locally
+// against the published JWKS or by asking the introspection endpoint.
+//
+//	client, err := authclient.New(authclient.Config{
+//		JWKSURL: "http://auth:3000/.well-known/jwks.json",
+//	})
+//	app.Use(client.FiberMiddleware())
+package authclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultJWKSRefreshInterval   = 5 * time.Minute
+	defaultJWKSMinRefetch        = 10 * time.Second
+	defaultIntrospectionCacheTTL = 30 * time.Second
+	defaultHTTPTimeout           = 5 * time.Second
+)
+
+var (
+	ErrMissingToken  = errors.New("authclient: missing token")
+	ErrInvalidToken  = errors.New("authclient: invalid token")
+	ErrInactiveToken = errors.New("authclient: token is not active")
+	ErrNoVerifier    = errors.New("authclient: either JWKSURL or IntrospectionURL is required")
+)
+
+// Config selects how tokens are verified. When IntrospectionURL is set every
+// token is checked by the auth service, which also catches revoked
+// sessions; otherwise tokens are verified locally with the keys at JWKSURL.
+type Config struct {
+	JWKSURL string
+
+	IntrospectionURL string
+	ClientID         string
+	ClientSecret     string
+
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp and nbf.
+	Leeway time.Duration
+
+	JWKSRefreshInterval time.Duration
+	// JWKSMinRefetchInterval keeps tokens with unknown key ids from
+	// hammering the JWKS endpoint.
+	JWKSMinRefetchInterval time.Duration
+	IntrospectionCacheTTL  time.Duration
+	HTTPClient             *http.Client
+}
+
+// Claims are the verified details of a token.
+type Claims struct {
+	Subject   string    `json:"sub"`
+	UserID    uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Scope     string    `json:"scope"`
+	SessionID string    `json:"jti"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// IsAdmin reports whether the token belongs to an admin.
+func (c *Claims) IsAdmin() bool {
+	return c.Role == "admin"
+}
+
+// Client verifies tokens. It is safe for concurrent use.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	keysMu      sync.RWMutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedIntrospection
+}
+
+type cachedIntrospection struct {
+	claims    *Claims
+	err       error
+	expiresAt time.Time
+}
+
+func New(config Config) (*Client, error) {
+	if config.JWKSURL == "" && config.IntrospectionURL == "" {
+		return nil, ErrNoVerifier
+	}
+	if config.JWKSRefreshInterval <= 0 {
+		config.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	}
+	if config.JWKSMinRefetchInterval <= 0 {
+		config.JWKSMinRefetchInterval = defaultJWKSMinRefetch
+	}
+	if config.IntrospectionCacheTTL <= 0 {
+		config.IntrospectionCacheTTL = defaultIntrospectionCacheTTL
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	return &Client{
+		config: config,
+		http:   httpClient,
+		keys:   map[string]interface{}{},
+		cache:  map[string]cachedIntrospection{},
+	}, nil
+}
+
+// Verify checks token and returns its claims.
+func (c *Client) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	if c.config.IntrospectionURL != "" {
+		return c.introspectCached(ctx, token)
+	}
+	return c.verifyLocally(ctx, token)
+}
+
+type tokenClaims struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	Scope string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+func (c *Client) verifyLocally(ctx context.Context, token string) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(c.config.Leeway),
+	}
+	if c.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(c.config.Issuer))
+	}
+	if c.config.Audience != "" {
+		options = append(options, jwt.WithAudience(c.config.Audience))
+	}
+
+	var parsed tokenClaims
+	_, err := jwt.ParseWithClaims(token, &parsed, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, kid)
+	}, options...)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidToken, err)
+	}
+
+	claims := &Claims{
+		Subject:   parsed.Subject,
+		UserID:    parsed.ID,
+		Name:      parsed.Name,
+		Email:     parsed.Email,
+		Role:      parsed.Role,
+		Scope:     parsed.Scope,
+		SessionID: parsed.RegisteredClaims.ID,
+	}
+	if claims.Subject == "" && claims.UserID != 0 {
+		claims.Subject = strconv.FormatUint(uint64(claims.UserID), 10)
+	}
+	if parsed.ExpiresAt != nil {
+		claims.ExpiresAt = parsed.ExpiresAt.Time
+	}
+	return claims, nil
+}
+
+func (c *Client) introspectCached(ctx context.Context, token string) (*Claims, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	c.cacheMu.Lock()
+	entry, ok := c.cache[key]
+	c.cacheMu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.claims, entry.err
+	}
+
+	claims, err := c.introspect(ctx, token)
+	if err != nil && !errors.Is(err, ErrInactiveToken) {
+		// Transport failures are not cached.
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(c.config.IntrospectionCacheTTL)
+	if claims != nil && !claims.ExpiresAt.IsZero() && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt
+	}
+
+	c.cacheMu.Lock()
+	now := time.Now()
+	for k, e := range c.cache {
+		if now.After(e.expiresAt) {
+			delete(c.cache, k)
+		}
+	}
+	c.cache[key] = cachedIntrospection{claims: claims, err: err, expiresAt: expiresAt}
+	c.cacheMu.Unlock()
+
+	return claims, err
+}
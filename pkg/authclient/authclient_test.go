@@ -0,0 +1,388 @@
+package authclient_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"micro/pkg/authclient"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeAuthService mimics the JWKS and introspection endpoints of the auth
+// service in-process.
+type fakeAuthService struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	current string
+	revoked map[string]bool
+
+	jwksHits          atomic.Int32
+	introspectionHits atomic.Int32
+}
+
+const (
+	testClientID     = "svc_test"
+	testClientSecret = "s3cret"
+)
+
+func newFakeAuthService(t *testing.T) *fakeAuthService {
+	t.Helper()
+
+	f := &fakeAuthService{t: t, keys: map[string]*rsa.PrivateKey{}, revoked: map[string]bool{}}
+	f.rotate()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jwks.json", f.handleJWKS)
+	mux.HandleFunc("/api/oauth/introspect", f.handleIntrospect)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeAuthService) rotate() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		f.t.Fatalf("generate key: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kid := "key-" + strconv.Itoa(len(f.keys)+1)
+	f.keys[kid] = key
+	f.current = kid
+	return kid
+}
+
+func (f *fakeAuthService) issue(userID uint, role, sessionID string, expiresIn time.Duration) string {
+	f.mu.Lock()
+	kid, key := f.current, f.keys[f.current]
+	f.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"id":    userID,
+		"jti":   sessionID,
+		"name":  "Jane Doe",
+		"email": "jane@example.com",
+		"role":  role,
+		"exp":   time.Now().Add(expiresIn).Unix(),
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		f.t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func (f *fakeAuthService) revoke(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[sessionID] = true
+}
+
+func (f *fakeAuthService) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	f.jwksHits.Add(1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var keys []map[string]string
+	for kid, key := range f.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+func (f *fakeAuthService) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	f.introspectionHits.Add(1)
+
+	if id, secret, ok := r.BasicAuth(); !ok || id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var claims jwt.MapClaims
+	_, err := jwt.ParseWithClaims(r.FormValue("token"), &claims, func(t *jwt.Token) (interface{}, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return &f.keys[t.Header["kid"].(string)].PublicKey, nil
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	sessionID, _ := claims["jti"].(string)
+	f.mu.Lock()
+	revoked := f.revoked[sessionID]
+	f.mu.Unlock()
+	if err != nil || revoked {
+		json.NewEncoder(w).Encode(map[string]interface{}{"active": false, "revoked": revoked})
+		return
+	}
+
+	exp, _ := claims.GetExpirationTime()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active":   true,
+		"sub":      strconv.Itoa(int(claims["id"].(float64))),
+		"username": claims["email"],
+		"role":     claims["role"],
+		"scope":    "profile email",
+		"exp":      exp.Unix(),
+		"jti":      sessionID,
+	})
+}
+
+func newClient(t *testing.T, config authclient.Config) *authclient.Client {
+	t.Helper()
+	client, err := authclient.New(config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return client
+}
+
+func TestNewRequiresVerifier(t *testing.T) {
+	if _, err := authclient.New(authclient.Config{}); !errors.Is(err, authclient.ErrNoVerifier) {
+		t.Fatalf("expected ErrNoVerifier, got %v", err)
+	}
+}
+
+func TestVerifyWithJWKS(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{JWKSURL: fake.server.URL + "/.well-known/jwks.json"})
+
+	claims, err := client.Verify(context.Background(), fake.issue(42, "admin", "session-1", time.Hour))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if claims.UserID != 42 || claims.Subject != "42" {
+		t.Errorf("unexpected user: id=%d sub=%q", claims.UserID, claims.Subject)
+	}
+	if claims.Email != "jane@example.com" || claims.SessionID != "session-1" || !claims.IsAdmin() {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyWithJWKSCachesKeys(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{JWKSURL: fake.server.URL + "/.well-known/jwks.json"})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Verify(context.Background(), fake.issue(1, "member", "s", time.Hour)); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	}
+	if hits := fake.jwksHits.Load(); hits != 1 {
+		t.Fatalf("expected a single JWKS fetch, got %d", hits)
+	}
+}
+
+func TestVerifyWithJWKSPicksUpRotatedKey(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{
+		JWKSURL:                fake.server.URL + "/.well-known/jwks.json",
+		JWKSMinRefetchInterval: time.Nanosecond,
+	})
+
+	if _, err := client.Verify(context.Background(), fake.issue(1, "member", "s", time.Hour)); err != nil {
+		t.Fatalf("Verify before rotation: %v", err)
+	}
+
+	fake.rotate()
+	if _, err := client.Verify(context.Background(), fake.issue(1, "member", "s", time.Hour)); err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	if hits := fake.jwksHits.Load(); hits != 2 {
+		t.Fatalf("expected the JWKS to be refetched once, got %d fetches", hits)
+	}
+}
+
+func TestVerifyWithJWKSRejectsInvalidTokens(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{JWKSURL: fake.server.URL + "/.well-known/jwks.json"})
+
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":  1,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("shared"))
+
+	tests := map[string]string{
+		"expired":  fake.issue(1, "member", "s", -time.Hour),
+		"garbage":  "not-a-jwt",
+		"hs256":    hmacToken,
+		"tampered": fake.issue(1, "member", "s", time.Hour) + "x",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.Verify(context.Background(), token); !errors.Is(err, authclient.ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+
+	if _, err := client.Verify(context.Background(), ""); !errors.Is(err, authclient.ErrMissingToken) {
+		t.Fatalf("expected ErrMissingToken, got %v", err)
+	}
+}
+
+func TestVerifyWithJWKSChecksIssuerAndAudience(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{
+		JWKSURL:  fake.server.URL + "/.well-known/jwks.json",
+		Issuer:   "auth-service",
+		Audience: "orders",
+	})
+
+	if _, err := client.Verify(context.Background(), fake.issue(1, "member", "s", time.Hour)); !errors.Is(err, authclient.ErrInvalidToken) {
+		t.Fatalf("expected a token without iss/aud to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyWithIntrospection(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{
+		IntrospectionURL: fake.server.URL + "/api/oauth/introspect",
+		ClientID:         testClientID,
+		ClientSecret:     testClientSecret,
+	})
+
+	token := fake.issue(7, "member", "session-7", time.Hour)
+	claims, err := client.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.UserID != 7 || claims.SessionID != "session-7" || claims.Scope != "profile email" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	if _, err := client.Verify(context.Background(), token); err != nil {
+		t.Fatalf("second Verify: %v", err)
+	}
+	if hits := fake.introspectionHits.Load(); hits != 1 {
+		t.Fatalf("expected the second result to be cached, got %d introspection calls", hits)
+	}
+}
+
+func TestVerifyWithIntrospectionSeesRevocation(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{
+		IntrospectionURL: fake.server.URL + "/api/oauth/introspect",
+		ClientID:         testClientID,
+		ClientSecret:     testClientSecret,
+	})
+
+	fake.revoke("session-8")
+	_, err := client.Verify(context.Background(), fake.issue(8, "member", "session-8", time.Hour))
+	if !errors.Is(err, authclient.ErrInactiveToken) {
+		t.Fatalf("expected ErrInactiveToken, got %v", err)
+	}
+}
+
+func TestVerifyWithIntrospectionBadCredentials(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{
+		IntrospectionURL: fake.server.URL + "/api/oauth/introspect",
+		ClientID:         testClientID,
+		ClientSecret:     "wrong",
+	})
+
+	_, err := client.Verify(context.Background(), fake.issue(1, "member", "s", time.Hour))
+	if err == nil || errors.Is(err, authclient.ErrInactiveToken) {
+		t.Fatalf("expected a transport error, got %v", err)
+	}
+}
+
+func TestFiberMiddleware(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{JWKSURL: fake.server.URL + "/.well-known/jwks.json"})
+
+	app := fiber.New()
+	app.Get("/me", client.FiberMiddleware(), func(c *fiber.Ctx) error {
+		claims, ok := authclient.FiberClaims(c)
+		if !ok {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(claims.Email)
+	})
+
+	token := fake.issue(3, "member", "s", time.Hour)
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"bearer", fiber.HeaderAuthorization, "Bearer " + token, fiber.StatusOK},
+		{"x-token", "x-token", token, fiber.StatusOK},
+		{"missing", "", "", fiber.StatusUnauthorized},
+		{"invalid", fiber.HeaderAuthorization, "Bearer nope", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	fake := newFakeAuthService(t)
+	client := newClient(t, authclient.Config{JWKSURL: fake.server.URL + "/.well-known/jwks.json"})
+
+	handler := client.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authclient.ClaimsFromContext(r.Context())
+		if !ok || claims.UserID != 5 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+fake.issue(5, "member", "s", time.Hour))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Revoked  bool   `json:"revoked"`
+	Sub      string `json:"sub"`
+	Username string `json:"username"`
+	Scope    string `json:"scope"`
+	Role     string `json:"role"`
+	Exp      int64  `json:"exp"`
+	Jti      string `json:"jti"`
+}
+
+func (c *Client) introspect(ctx context.Context, token string) (*Claims, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspect token: status code %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode introspection response: %w", err)
+	}
+
+	if !result.Active {
+		return nil, ErrInactiveToken
+	}
+
+	claims := &Claims{
+		Subject:   result.Sub,
+		Email:     result.Username,
+		Role:      result.Role,
+		Scope:     result.Scope,
+		SessionID: result.Jti,
+	}
+	if id, err := strconv.ParseUint(result.Sub, 10, 64); err == nil {
+		claims.UserID = uint(id)
+	}
+	if result.Exp > 0 {
+		claims.ExpiresAt = time.Unix(result.Exp, 0)
+	}
+	return claims, nil
+}
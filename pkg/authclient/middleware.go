@@ -0,0 +1,83 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type contextKey struct{}
+
+// localsKey is where FiberMiddleware stores the claims.
+const localsKey = "authclient.claims"
+
+// TokenFromRequest extracts a token from an "Authorization: Bearer" header
+// or, for older clients, the x-token header.
+func TokenFromRequest(authorization, xToken string) string {
+	if scheme, token, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return xToken
+}
+
+// FiberMiddleware rejects requests without a valid token and makes the
+// claims available through FiberClaims.
+func (c *Client) FiberMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		token := TokenFromRequest(ctx.Get(fiber.HeaderAuthorization), ctx.Get("x-token"))
+		claims, err := c.Verify(ctx.UserContext(), token)
+		if err != nil {
+			status, message := errorStatus(err)
+			return ctx.Status(status).JSON(fiber.Map{"message": message})
+		}
+
+		ctx.Locals(localsKey, claims)
+		ctx.SetUserContext(WithClaims(ctx.UserContext(), claims))
+		return ctx.Next()
+	}
+}
+
+// FiberClaims returns the claims stored by FiberMiddleware.
+func FiberClaims(ctx *fiber.Ctx) (*Claims, bool) {
+	claims, ok := ctx.Locals(localsKey).(*Claims)
+	return claims, ok
+}
+
+// HTTPMiddleware is the net/http counterpart of FiberMiddleware. Claims are
+// available through ClaimsFromContext.
+func (c *Client) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := TokenFromRequest(r.Header.Get("Authorization"), r.Header.Get("x-token"))
+		claims, err := c.Verify(r.Context(), token)
+		if err != nil {
+			status, message := errorStatus(err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"message":"` + message + `"}`))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+	})
+}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrMissingToken), errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInactiveToken):
+		return http.StatusUnauthorized, "Unauthorized"
+	default:
+		return http.StatusServiceUnavailable, "Authentication unavailable"
+	}
+}